@@ -1,7 +1,8 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"io/fs"
 )
 
 type ConfigFile string
@@ -16,7 +17,7 @@ func (b *ConfigFileValue) String() string { return b.defPath }
 func (b *ConfigFileValue) Type() string   { return "configfile" }
 func (b *ConfigFileValue) Set(s string) (err error) {
 	if b.path = s; b.path != "" {
-		if err = Decode(s, b.referer); os.IsNotExist(err) {
+		if err = DecodeWith(s, b.referer, SkipValidate()); errors.Is(err, fs.ErrNotExist) {
 			err = nil
 		}
 	}
@@ -1,73 +1,432 @@
-package config
-
-import (
-	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
-)
-
-var decoder = DecoderFactory{}
-
-func Decode(source string, v any) error { return decoder.Decode(source, v) }
-
-func Registry(name string, unmarshal DecodeFunc, exts ...string) {
-	decoder.Register(name, unmarshal, exts...)
-}
-
-type (
-	DecoderFactory map[string]DecodeFunc
-	ReadFunc       = func(data []byte) (err error)
-	DecodeFunc     = func(value any) ReadFunc
-)
-
-func (f DecoderFactory) Register(name string, decodeFunc DecodeFunc, exts ...string) {
-	name = strings.ToLower(name)
-	f[name] = decodeFunc
-	for _, ext := range exts {
-		f[strings.ToLower(ext)] = decodeFunc
-	}
-}
-
-func (f DecoderFactory) Decode(source string, v any) (err error) {
-	if source == "" {
-		err = fmt.Errorf("source is empty")
-		return
-	}
-
-	var unmarshal DecodeFunc
-	var path string
-
-	if n, p, ok := strings.Cut(source, ":"); ok {
-		if unmarshal = f[n]; unmarshal != nil {
-			path = p
-		}
-	}
-
-	if unmarshal == nil {
-		if unmarshal = f[filepath.Ext(source)]; unmarshal != nil {
-			path = source
-		}
-	}
-
-	if unmarshal == nil {
-		err = fmt.Errorf("unsupport source: %s", source)
-		return
-	}
-
-	return f.readBytes(path, unmarshal(v))
-}
-
-func (f DecoderFactory) readBytes(path string, read ReadFunc) (err error) {
-	if path == "" {
-		err = fmt.Errorf("source path is empty")
-		return
-	}
-
-	var data []byte
-	if data, err = os.ReadFile(path); err != nil {
-		return
-	}
-
-	return read(data)
-}
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var decoder = &Codec{}
+
+func Decode(source string, v any) error { return decoder.Decode(source, v) }
+
+func DecodeWith(source string, v any, opts ...DecodeOption) error {
+	return decoder.DecodeWith(source, v, opts...)
+}
+
+func DecodeLayers(v any, sources ...string) error { return decoder.DecodeLayers(v, sources...) }
+
+func DecodeLayersWith(v any, sources []string, opts ...DecodeOption) error {
+	return decoder.DecodeLayersWith(v, sources, opts...)
+}
+
+func Encode(source string, v any) error { return decoder.Encode(source, v) }
+
+func Marshal(format string, v any) ([]byte, error) { return decoder.Marshal(format, v) }
+
+func Registry(name string, unmarshal DecodeFunc, exts ...string) {
+	decoder.Register(name, unmarshal, exts...)
+}
+
+// SetExpand 指定格式（名称与扩展名）在全局解码器中读取后展开 ${VAR}，默认不展开
+func SetExpand(name string, exts ...string) { decoder.SetExpand(name, exts...) }
+
+// SetEnv 设置全局解码器使用的环境变量来源，为空时使用进程环境变量
+//
+//	${VAR} 展开、env: 配置源、env: 敏感值引用与 dotenv 中的变量引用均通过该来源查找。
+func SetEnv(lookup LookupEnvFunc) { decoder.SetEnv(lookup) }
+
+// LookupEnv 通过全局解码器的环境变量来源查找变量
+func LookupEnv(key string) (string, bool) { return decoder.LookupEnv(key) }
+
+func RegistryEncoder(name string, marshal EncodeFunc, exts ...string) {
+	decoder.RegisterEncoder(name, marshal, exts...)
+}
+
+type (
+	// DecoderFactory 格式名称与扩展名到解码函数的映射
+	DecoderFactory map[string]DecodeFunc
+
+	// Codec 在 DecoderFactory 的基础上管理编码函数、配置来源、敏感值解析器与严格模式，
+	// 零值可直接使用，包级函数使用同一个全局 Codec
+	Codec struct {
+		decoders  DecoderFactory
+		encoders  map[string]EncodeFunc
+		sources   map[string]Source
+		resolvers map[string]SecretResolver
+		formats   map[string]string
+		lookupEnv LookupEnvFunc
+		expand    map[string]bool
+
+		stricts       map[string]DecodeFunc
+		strictExts    map[string][]string
+		strictFormats map[string]bool
+	}
+	ReadFunc   = func(data []byte) (err error)
+	DecodeFunc = func(value any) ReadFunc
+	EncodeFunc = func(value any) (data []byte, err error)
+)
+
+func (f DecoderFactory) Register(name string, decodeFunc DecodeFunc, exts ...string) {
+	register(f, decodeFunc, name, exts...)
+}
+
+// Decode 使用 f 中注册的解码函数解码配置源，其余行为与 Codec.Decode 一致
+func (f DecoderFactory) Decode(source string, v any) error {
+	return (&Codec{decoders: f}).Decode(source, v)
+}
+
+// DecodeLayers 使用 f 中注册的解码函数按顺序解码并合并多个配置源，其余行为与 Codec.DecodeLayers 一致
+func (f DecoderFactory) DecodeLayers(v any, sources ...string) error {
+	return (&Codec{decoders: f}).DecodeLayers(v, sources...)
+}
+
+func (f DecoderFactory) DecodeLayersWith(v any, sources []string, opts ...DecodeOption) error {
+	return (&Codec{decoders: f}).DecodeLayersWith(v, sources, opts...)
+}
+
+// Encode 编码 v 并写回配置源，DecoderFactory 不包含编码函数，使用全局注册的编码函数
+func (f DecoderFactory) Encode(source string, v any) error {
+	return (&Codec{decoders: f, encoders: decoder.encoders}).Encode(source, v)
+}
+
+func (f *Codec) Register(name string, decodeFunc DecodeFunc, exts ...string) {
+	if f.decoders == nil {
+		f.decoders = DecoderFactory{}
+	}
+	f.decoders.Register(name, decodeFunc, exts...)
+	f.formats = register(f.formats, strings.ToLower(name), name, exts...)
+}
+
+func (f *Codec) RegisterEncoder(name string, encodeFunc EncodeFunc, exts ...string) {
+	f.encoders = register(f.encoders, encodeFunc, name, exts...)
+}
+
+func (f *Codec) SetEnv(lookup LookupEnvFunc) { f.lookupEnv = lookup }
+
+func (f *Codec) LookupEnv(key string) (string, bool) {
+	if f.lookupEnv == nil {
+		return os.LookupEnv(key)
+	}
+	return f.lookupEnv(key)
+}
+
+func (f *Codec) SetExpand(name string, exts ...string) {
+	f.expand = register(f.expand, true, name, exts...)
+}
+
+// expands 判断配置源读取后是否需要展开 ${VAR}
+func (f *Codec) expands(source string) bool {
+	expand, _, _ := lookup(f.expand, source, f.sourceExt)
+	return expand
+}
+
+func register[T any](m map[string]T, fn T, name string, exts ...string) map[string]T {
+	if m == nil {
+		m = make(map[string]T)
+	}
+	m[strings.ToLower(name)] = fn
+	for _, ext := range exts {
+		m[strings.ToLower(ext)] = fn
+	}
+	return m
+}
+
+// DecodeOption 单次解码的选项
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	skipValidate bool
+	strict       bool
+	optional     []string
+}
+
+// SkipValidate 解码后不执行 validate 标签校验，用于还需要叠加其它来源再统一校验的场景
+func SkipValidate() DecodeOption { return func(o *decodeOptions) { o.skipValidate = true } }
+
+// Optional 指定的配置源为可选，不存在时跳过而不是返回错误
+func Optional(sources ...string) DecodeOption {
+	return func(o *decodeOptions) { o.optional = append(o.optional, sources...) }
+}
+
+func newDecodeOptions(opts []DecodeOption) (o decodeOptions) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return
+}
+
+// skip 判断解码 source 时遇到的错误是否因可选配置源本身不存在而可以忽略，
+// 配置源存在但其引入的文件不存在时不忽略
+func (o decodeOptions) skip(source string, err error) bool {
+	re, ok := err.(*readError)
+	return ok && errors.Is(re.err, os.ErrNotExist) && slices.Contains(o.optional, source)
+}
+
+// readError 读取配置源本身失败的错误，decode 直接返回，不经过 include 的包装
+type readError struct{ err error }
+
+func (e *readError) Error() string { return e.err.Error() }
+func (e *readError) Unwrap() error { return e.err }
+
+func (f *Codec) Decode(source string, v any) error { return f.DecodeWith(source, v) }
+
+func (f *Codec) DecodeWith(source string, v any, opts ...DecodeOption) (err error) {
+	o := newDecodeOptions(opts)
+	d, err := f.document(source, o.strict)
+	if err != nil {
+		return
+	}
+
+	st := &decodeState{strict: o.strict, origins: map[string]string{}}
+	if _, err = f.decode(d, v, nil, st); err != nil {
+		if o.skip(source, err) {
+			err = nil
+		}
+		return
+	}
+
+	if err = f.ResolveSecrets(v); err != nil || o.skipValidate {
+		return
+	}
+	return validate(v, st.origins, d.path)
+}
+
+// decodeState 一次解码过程中共享的状态
+type decodeState struct {
+	strict  bool              // 是否使用严格模式
+	origins map[string]string // 字段路径对应的来源文件
+}
+
+// document 一个待解码的配置文档
+type document struct {
+	unmarshal DecodeFunc // 解码到目标类型的函数，严格模式时为严格模式解码函数
+	tree      DecodeFunc // 解码为通用结构的函数
+	path      string
+	format    string // 格式名称，同时是合并时匹配字段使用的标签，未知时为空
+	expand    bool
+}
+
+// document 解析配置源对应的格式、路径与解码函数
+func (f *Codec) document(source string, strict bool) (d document, err error) {
+	if d.tree, d.path, err = f.resolve(source); err != nil {
+		return
+	}
+	if d.unmarshal, _, err = f.resolveFor(source, strict); err != nil {
+		return
+	}
+	d.format, _, _ = lookup(f.formats, source, f.sourceExt)
+	d.expand = f.expands(source)
+	return
+}
+
+// decode 读取并解码 d 到 v，随后按顺序合并其中 include 指令引入的配置，chain 为当前的引入链，用于检测循环引入
+//
+//	返回文档的通用结构，用于按键是否出现合并配置层；格式无法解码为 map[string]any 时为 nil。
+func (f *Codec) decode(d document, v any, chain []string, st *decodeState) (tree map[string]any, err error) {
+	var data []byte
+	if data, err = f.readBytes(d.path, d.expand); err != nil {
+		err = &readError{err}
+		return
+	}
+
+	var includes []string
+	if d.tree(&tree)(data) != nil {
+		tree = nil
+	} else if includes, err = takeIncludes(tree, reflect.TypeOf(v), d.format); err != nil {
+		err = fmt.Errorf("%s: %w", d.path, err)
+		return
+	}
+
+	if err = d.unmarshal(v)(data); err != nil {
+		var se *StrictError
+		if errors.As(err, &se) && se.Source == "" {
+			se.Source = d.path
+		}
+		return
+	}
+	recordOrigins(st.origins, reflect.ValueOf(v), d.path)
+
+	if len(includes) > 0 {
+		tree, err = f.include(d, includes, v, tree, chain, st)
+	}
+	return
+}
+
+// resolve 解析配置源的格式前缀与路径
+func (f *Codec) resolve(source string) (unmarshal DecodeFunc, path string, err error) {
+	return lookup(f.decoders, source, f.sourceExt)
+}
+
+// resolveFor 按是否严格模式解析配置源，格式被设置为始终严格时同样使用严格模式
+func (f *Codec) resolveFor(source string, strict bool) (unmarshal DecodeFunc, path string, err error) {
+	if strict || f.isStrict(source) {
+		return f.resolveStrict(source)
+	}
+	return f.resolve(source)
+}
+
+// lookup 按格式前缀或扩展名查找格式对应的函数，返回去掉格式前缀后的配置源
+func lookup[T any](m map[string]T, source string, ext func(string) string) (fn T, path string, err error) {
+	if source == "" {
+		err = fmt.Errorf("source is empty")
+		return
+	}
+
+	var ok bool
+	if n, p, found := strings.Cut(source, ":"); found {
+		if fn, ok = m[n]; ok {
+			path = p
+		}
+	}
+
+	if !ok {
+		if fn, ok = m[strings.ToLower(ext(source))]; ok {
+			path = source
+		}
+	}
+
+	if !ok {
+		err = fmt.Errorf("unsupport source: %s", source)
+	}
+	return
+}
+
+func (f *Codec) DecodeLayers(v any, sources ...string) error { return f.DecodeLayersWith(v, sources) }
+
+// DecodeLayersWith 按顺序解码多个配置源并深度合并到 v
+//
+//	后面的配置源覆盖前面的配置源中出现的键，即使值为 false、0 或空字符串；
+//	通过 Optional 指定的配置源不存在时跳过，使用 Strict 时每一层都按严格模式解码。
+//	所有层合并完成后再解析敏感字段引用并按 validate 标签校验。
+func (f *Codec) DecodeLayersWith(v any, sources []string, opts ...DecodeOption) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		err = fmt.Errorf("decode layers into non-pointer %T", v)
+		return
+	}
+
+	o := newDecodeOptions(opts)
+	st := &decodeState{strict: o.strict, origins: map[string]string{}}
+	for _, source := range sources {
+		d, e := f.document(source, o.strict)
+		if e != nil {
+			err = e
+			return
+		}
+
+		layer := reflect.New(rv.Type().Elem())
+		tree, e := f.decode(d, layer.Interface(), nil, st)
+		if e != nil {
+			if o.skip(source, e) {
+				continue
+			}
+			err = fmt.Errorf("decode layer %s: %w", source, e)
+			return
+		}
+
+		mergeLayer(rv.Elem(), layer.Elem(), tree, d.format)
+	}
+
+	if err = f.ResolveSecrets(v); err != nil || o.skipValidate {
+		return
+	}
+	return validate(v, st.origins, strings.Join(sources, ", "))
+}
+
+func (f *Codec) readBytes(path string, expand bool) (data []byte, err error) {
+	if path == "" {
+		err = fmt.Errorf("source path is empty")
+		return
+	}
+
+	if data, err = f.readSource(path); err != nil || !expand {
+		return
+	}
+
+	if data, err = Expand(data, f.LookupEnv); err != nil {
+		err = fmt.Errorf("expand %s: %w", path, err)
+	}
+	return
+}
+
+// Encode 将 v 编码后写回配置源
+//
+//	先写入同目录下的临时文件再重命名，保证写入的原子性，并保留原文件的权限；
+//	配置文件为符号链接时写入其指向的文件。
+func (f *Codec) Encode(source string, v any) (err error) {
+	marshal, path, err := lookup(f.encoders, source, f.sourceExt)
+	if err != nil {
+		return
+	}
+
+	if path == "" {
+		err = fmt.Errorf("source path is empty")
+		return
+	}
+
+	local, ok := f.localPath(path)
+	if !ok {
+		err = fmt.Errorf("encode to non-file source: %s", source)
+		return
+	}
+
+	var data []byte
+	if data, err = marshal(v); err != nil {
+		return
+	}
+
+	return writeFile(local, data)
+}
+
+// Marshal 使用格式名称或扩展名对应的编码函数编码 v
+func (f *Codec) Marshal(format string, v any) (data []byte, err error) {
+	marshal, ok := f.encoders[strings.ToLower(format)]
+	if !ok {
+		err = fmt.Errorf("unsupport format: %s", format)
+		return
+	}
+	return marshal(v)
+}
+
+func writeFile(path string, data []byte) (err error) {
+	// 配置文件为符号链接时写入链接指向的文件，保留链接本身
+	if real, e := filepath.EvalSymlinks(path); e == nil {
+		path = real
+	}
+
+	perm := os.FileMode(0644)
+	if fi, e := os.Stat(path); e == nil {
+		perm = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err == nil {
+		if err = tmp.Chmod(perm); err == nil {
+			err = tmp.Sync()
+		}
+	}
+
+	if ce := tmp.Close(); err == nil {
+		err = ce
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	return
+}
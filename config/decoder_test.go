@@ -0,0 +1,487 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type testConfig struct {
+	Name   string            `json:"name"`
+	Port   int               `json:"port"`
+	Tags   []string          `json:"tags"`
+	Mounts []string          `json:"mounts" merge:"append"`
+	Labels map[string]string `json:"labels"`
+	DB     *testDB           `json:"db"`
+	Debug  bool              `json:"debug"`
+}
+
+type testDB struct {
+	Host string `json:"host"`
+	User string `json:"user"`
+}
+
+func testFactory() *Codec {
+	f := &Codec{}
+	f.Register("json", func(v any) ReadFunc {
+		return func(data []byte) error { return json.Unmarshal(data, v) }
+	}, ".json")
+	f.RegisterEncoder("json", func(v any) ([]byte, error) { return json.Marshal(v) }, ".json")
+	return f
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDecodeLayers(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"system.json": `{"name":"nas","port":80,"tags":["a"],"mounts":["/data"],"labels":{"zone":"a","rack":"1"},"db":{"host":"db","user":"root"},"debug":true}`,
+		"site.json":   `{"port":8080,"tags":["b","c"],"mounts":["/backup"],"labels":{"rack":"2"},"db":{"host":"","user":"nas"},"debug":false}`,
+	})
+
+	var cfg testConfig
+	host := filepath.Join(dir, "host.json")
+	err := testFactory().DecodeLayersWith(&cfg,
+		[]string{filepath.Join(dir, "system.json"), filepath.Join(dir, "site.json"), host},
+		Optional(host),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := testConfig{
+		Name:   "nas",
+		Port:   8080,
+		Tags:   []string{"b", "c"},
+		Mounts: []string{"/data", "/backup"},
+		Labels: map[string]string{"zone": "a", "rack": "2"},
+		DB:     &testDB{User: "nas"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	if err = testFactory().DecodeLayers(&cfg, filepath.Join(dir, "host.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("required layer missing, got %v", err)
+	}
+
+	// 可选层存在但引入的文件不存在时不能被跳过
+	broken := filepath.Join(dir, "broken.json")
+	if err = os.WriteFile(broken, []byte(`{"include": "missing.json"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = testFactory().DecodeLayersWith(&cfg, []string{broken}, Optional(broken)); err == nil {
+		t.Fatal("missing include of an optional layer must fail")
+	}
+
+	// 无法解码为通用结构的格式按非零值合并，没有导出字段的结构体整体替换
+	type stamped struct{ At time.Time }
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var dst stamped
+	merge(reflect.ValueOf(&dst).Elem(), reflect.ValueOf(stamped{At: at}), false)
+	if !dst.At.Equal(at) {
+		t.Fatalf("time not merged: %v", dst.At)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.json": `{"name":"old"}`})
+	path := filepath.Join(dir, "app.json")
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := testFactory()
+	want := testConfig{Name: "new", Port: 8080, Labels: map[string]string{"zone": "a"}}
+	if err := f.Encode(path, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("file mode not preserved: %v %v", fi.Mode(), err)
+	}
+
+	var got testConfig
+	if err := f.Decode(path, &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v, err %v", got, want, err)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("temp file left behind: %v", entries)
+	}
+
+	link := filepath.Join(dir, "link.json")
+	if err := os.Symlink(path, link); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Encode(link, &testConfig{Name: "linked"}); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink replaced: %v %v", fi.Mode(), err)
+	}
+	if err := f.Decode(path, &got); err != nil || got.Name != "linked" {
+		t.Fatalf("link target not written: %+v %v", got, err)
+	}
+}
+
+func TestDecoderFactory(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.json": `{"name":"nas"}`, "site.json": `{"port":80}`})
+
+	f := DecoderFactory{"json": func(v any) ReadFunc {
+		return func(data []byte) error { return json.Unmarshal(data, v) }
+	}}
+	f.Register("jsonc", f["json"], ".json")
+
+	var cfg testConfig
+	if err := f.Decode(filepath.Join(dir, "app.json"), &cfg); err != nil || cfg.Name != "nas" {
+		t.Fatalf("got %+v, err %v", cfg, err)
+	}
+	cfg = testConfig{}
+	if err := f.DecodeLayers(&cfg, filepath.Join(dir, "app.json"), filepath.Join(dir, "site.json")); err != nil || cfg.Name != "nas" || cfg.Port != 80 {
+		t.Fatalf("layers: got %+v, err %v", cfg, err)
+	}
+
+	RegistryEncoder("json", func(v any) ([]byte, error) { return json.Marshal(v) }, ".json")
+	if err := f.Encode(filepath.Join(dir, "out.json"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out.json")); !strings.Contains(string(data), `"port":80`) {
+		t.Fatalf("encoded: %s", data)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"DATA_DIR": "/data", "EMPTY": ""}
+	lookup := func(k string) (v string, ok bool) { v, ok = env[k]; return }
+
+	out, err := Expand([]byte(`dir: ${DATA_DIR}/cache
+log: ${LOG_DIR:-${DATA_DIR}/log}
+empty: ${EMPTY:-none}
+raw: $${DATA_DIR} $HOME
+unset: ${UNSET} ${EMPTY}`), lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `dir: /data/cache
+log: /data/log
+empty: none
+raw: ${DATA_DIR} $HOME
+unset: ${UNSET} `
+	if string(out) != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+
+	if _, err = Expand([]byte(`a: ${TOKEN:?token is required} b: ${EMPTY:?}`), lookup); err == nil ||
+		!strings.Contains(err.Error(), "TOKEN: token is required") || !strings.Contains(err.Error(), "EMPTY: required") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.json": `{
+  "$import": ["conf.d/*.json"],
+  "name": "nas",
+  "port": 80
+}`,
+		"multiline.json": `{
+  "name": "nas",
+  "$import": [
+    "conf.d/*.json"
+  ]
+}`,
+		"inline.json":         `{"$import": "conf.d/10-port.json", "name": "nas", "debug": true}`,
+		"conf.d/10-port.json": `{"port": 8080, "debug": false}`,
+		"conf.d/20-db.json":   `{"db": {"host": "db"}}`,
+		"nested.json":         `{"name": "nas", "labels": {"include": "x"}}`,
+		"loop/a.json":         "{\n  \"include\": \"b.json\",\n  \"name\": \"a\"\n}",
+		"loop/b.json":         "{\n  \"include\": \"a.json\",\n  \"name\": \"b\"\n}",
+	})
+
+	for name, want := range map[string]testConfig{
+		"app.json":       {Name: "nas", Port: 8080, DB: &testDB{Host: "db"}},
+		"multiline.json": {Name: "nas", Port: 8080, DB: &testDB{Host: "db"}},
+		"inline.json":    {Name: "nas", Port: 8080},
+		"nested.json":    {Name: "nas", Labels: map[string]string{"include": "x"}},
+	} {
+		var cfg testConfig
+		if err := testFactory().Decode(filepath.Join(dir, name), &cfg); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Fatalf("%s: got %+v, want %+v", name, cfg, want)
+		}
+	}
+
+	var cfg testConfig
+
+	if err := testFactory().Decode(filepath.Join(dir, "loop/a.json"), &cfg); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type server struct {
+		Host  string        `json:"host" validate:"required"`
+		Port  int           `json:"port" validate:"required,min=1,max=65535"`
+		Mode  string        `json:"mode" validate:"oneof=ro rw"`
+		Idle  time.Duration `json:"idle" validate:"max=1m"`
+		Users []string      `json:"users" validate:"max=2"`
+	}
+	type config struct {
+		Servers []server `json:"servers"`
+		Token   string   `json:"token" validate:"required"`
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"base.json": `{"servers":[{"host":"a","port":80,"mode":"rw"}],"token":"x"}`,
+		"site.json": `{"servers":[{"port":70000,"mode":"wo","idle":120000000000,"users":["a","b","c"]}]}`,
+	})
+
+	// servers 切片整体被 site.json 替换，因此缺失的 host 也归属于 site.json
+	var cfg config
+	err := testFactory().DecodeLayers(&cfg, filepath.Join(dir, "base.json"), filepath.Join(dir, "site.json"))
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	got := map[string]string{}
+	for _, v := range verr.Violations {
+		got[v.Path+" "+v.Rule] = filepath.Base(v.Source)
+	}
+
+	want := map[string]string{
+		"Servers[0].Host required":    "site.json",
+		"Servers[0].Port max=65535":   "site.json",
+		"Servers[0].Mode oneof=ro rw": "site.json",
+		"Servers[0].Idle max=1m":      "site.json",
+		"Servers[0].Users max=2":      "site.json",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSources(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.FS(fstest.MapFS{
+		"app.json": {Data: []byte(`{"port":8080}`)},
+	})))
+	defer srv.Close()
+
+	f := testFactory()
+	f.RegisterSource("fs", FS(fstest.MapFS{"defaults/app.json": {Data: []byte(`{"name":"nas","port":80}`)}}))
+	f.SetEnv(func(k string) (string, bool) {
+		if k == "APP_CONFIG" {
+			return `{"labels":{"zone":"a"}}`, true
+		}
+		return "", false
+	})
+
+	var cfg testConfig
+	err := f.DecodeLayersWith(&cfg,
+		[]string{
+			"fs:defaults/app.json",
+			srv.URL + "/app.json?rev=1",
+			"json:env:APP_CONFIG",
+			"json:env:APP_CONFIG_OVERRIDE",
+			srv.URL + "/missing.json",
+		},
+		Optional("json:env:APP_CONFIG_OVERRIDE", srv.URL+"/missing.json"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := testConfig{Name: "nas", Port: 8080, Labels: map[string]string{"zone": "a"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	if err = f.Encode("fs:defaults/app.json", &cfg); err == nil {
+		t.Fatal("encode to fs source must fail")
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	type config struct {
+		Password Secret            `json:"password"`
+		Token    string            `json:"token" secret:"1"`
+		Keys     map[string]Secret `json:"keys"`
+		Plain    string            `json:"plain"`
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"db.secret": "s3cret\n",
+		"app.json":  `{"password":"file:${SECRET_DIR}/db.secret","token":"env:APP_TOKEN","keys":{"a":"base64:cGFzcw=="},"plain":"env:APP_TOKEN"}`,
+	})
+	t.Setenv("SECRET_DIR", dir)
+	t.Setenv("APP_TOKEN", "tok")
+
+	f := testFactory()
+	f.SetExpand("json", ".json")
+
+	var cfg config
+	if err := f.Decode(filepath.Join(dir, "app.json"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := config{Password: "s3cret", Token: "tok", Keys: map[string]Secret{"a": "pass"}, Plain: "env:APP_TOKEN"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %#v, want %#v", cfg, want)
+	}
+
+	if s := fmt.Sprintf("%v", cfg.Password); s != "******" {
+		t.Fatalf("secret not masked: %s", s)
+	}
+
+	if r := Redact(&cfg).(*config); r.Token != "******" || r.Plain != cfg.Plain {
+		t.Fatalf("redact: %#v", r)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	type db struct {
+		Host string `yaml:"host" usage:"database host" default:"localhost"`
+		Pass Secret `yaml:"pass"`
+	}
+	type config struct {
+		Port    int               `yaml:"port" default:"80" validate:"required,min=1,max=65535"`
+		Mode    string            `yaml:"mode" validate:"oneof=ro rw"`
+		Timeout time.Duration     `yaml:"timeout"`
+		Tags    []string          `yaml:"tags" default:"a,b"`
+		Labels  map[string]string `yaml:"labels"`
+		DB      *db               `yaml:"db"`
+		Skip    string            `yaml:"-"`
+		Name    string
+	}
+
+	data, err := JSONSchema(&config{}, "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s struct {
+		Title      string                    `json:"title"`
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err = json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	port := s.Properties["port"]
+	if s.Title != "config" || !reflect.DeepEqual(s.Required, []string{"port"}) ||
+		port["type"] != "integer" || port["default"] != 80.0 || port["maximum"] != 65535.0 {
+		t.Fatalf("unexpected schema: %s", data)
+	}
+
+	if _, ok := s.Properties["name"]; !ok || len(s.Properties) != 10 {
+		t.Fatalf("unexpected properties: %s", data)
+	}
+
+	if enum := s.Properties["mode"]["enum"]; !reflect.DeepEqual(enum, []any{"ro", "rw"}) {
+		t.Fatalf("unexpected enum: %v", enum)
+	}
+
+	for _, key := range []string{"$schema", "$import", "include"} {
+		if _, ok := s.Properties[key]; !ok {
+			t.Fatalf("%s must be allowed: %s", key, data)
+		}
+	}
+
+	if data, err = JSONSchema(&struct {
+		Timeout time.Duration `json:"timeout" default:"1s"`
+	}{}, "json"); err != nil || !strings.Contains(string(data), `"type": "integer"`) || !strings.Contains(string(data), `"default": 1000000000`) {
+		t.Fatalf("json duration must be an integer: %s %v", data, err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	f := testFactory()
+	f.RegisterStrict("json", func(v any) ReadFunc {
+		return func(data []byte) error {
+			var tree map[string]any
+			if err := json.Unmarshal(data, &tree); err != nil {
+				return err
+			}
+			if errs := CheckKeys(tree, v, "json"); len(errs) > 0 {
+				return &StrictError{Errors: errs}
+			}
+			return json.Unmarshal(data, v)
+		}
+	}, ".json")
+
+	dir := writeFiles(t, map[string]string{
+		"app.json":  `{"name": "nas", "prot": 80, "tags": "a", "db": {"hots": "db"}}`,
+		"typo.json": `{"name": "nas", "prot": 80}`,
+	})
+	path := filepath.Join(dir, "app.json")
+
+	var cfg testConfig
+	if err := f.Decode(filepath.Join(dir, "typo.json"), &cfg); err != nil {
+		t.Fatalf("lenient decode: %v", err)
+	}
+
+	var se *StrictError
+	if err := f.DecodeWith(path, &cfg, Strict()); !errors.As(err, &se) {
+		t.Fatalf("expected strict error, got %v", err)
+	}
+
+	want := []string{"db.hots: unknown field", "prot: unknown field", `tags: cannot use "a" as []string`}
+	if got := keyErrors(se.Errors); !reflect.DeepEqual(got, want) || se.Source != path {
+		t.Fatalf("got %q from %s, want %q", got, se.Source, want)
+	}
+
+	if err := f.DecodeLayersWith(&cfg, []string{filepath.Join(dir, "typo.json")}, Strict()); !errors.As(err, &se) {
+		t.Fatalf("expected strict error from layers, got %v", err)
+	}
+
+	f.SetStrict("json")
+	if err := f.Decode(filepath.Join(dir, "typo.json"), &cfg); !errors.As(err, &se) {
+		t.Fatalf("expected strict error after SetStrict, got %v", err)
+	}
+
+	// yaml 与 ini 的解码器区分大小写，json 忽略大小写
+	var port struct {
+		Port int `json:"port" yaml:"port"`
+	}
+	tree := map[string]any{"Port": 80}
+	if got := keyErrors(CheckKeys(tree, &port, "yaml")); !reflect.DeepEqual(got, []string{"Port: unknown field"}) {
+		t.Fatalf("yaml keys must be case-sensitive, got %q", got)
+	}
+	if errs := CheckKeys(tree, &port, "json"); len(errs) > 0 {
+		t.Fatalf("json keys must be case-insensitive, got %q", keyErrors(errs))
+	}
+}
+
+func keyErrors(errs []KeyError) (out []string) {
+	for _, ke := range errs {
+		out = append(out, ke.String())
+	}
+	return
+}
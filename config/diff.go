@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change 描述配置中一个值的变化
+type Change struct {
+	Path string // 字段路径，如 DB.Host、Labels[zone]
+	Old  any    // 变化前的值，新增时为 nil
+	New  any    // 变化后的值，删除时为 nil
+}
+
+func (c Change) String() string { return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New) }
+
+// Diff 比较同一类型的两个配置值，返回所有发生变化的字段
+func Diff(old, new any) (changes []Change) {
+	diff("", reflect.ValueOf(old), reflect.ValueOf(new), &changes)
+	return
+}
+
+func diff(path string, a, b reflect.Value, changes *[]Change) {
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		if a.IsValid() != b.IsValid() || !reflect.DeepEqual(iface(a), iface(b)) {
+			*changes = append(*changes, Change{Path: path, Old: iface(a), New: iface(b)})
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*changes = append(*changes, Change{Path: path, Old: iface(a), New: iface(b)})
+			}
+			return
+		}
+		diff(path, a.Elem(), b.Elem(), changes)
+	case reflect.Struct:
+		if isOpaque(a.Type()) {
+			if !equal(a, b) {
+				*changes = append(*changes, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+			}
+			return
+		}
+		for i, t := 0, a.Type(); i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				diff(joinPath(path, f.Name), a.Field(i), b.Field(i), changes)
+			}
+		}
+	case reflect.Map:
+		keys := a.MapKeys()
+		for _, k := range b.MapKeys() {
+			if !a.MapIndex(k).IsValid() {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			diff(fmt.Sprintf("%s[%v]", path, k), a.MapIndex(k), b.MapIndex(k), changes)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*changes = append(*changes, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// isOpaque 判断结构体是否需要作为整体比较与合并：没有导出字段（如 time.Time、netip.Addr）或实现了 encoding.TextUnmarshaler
+func isOpaque(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// equal 比较同一类型的两个值，类型有 Equal(T) bool 方法（如 time.Time）时使用该方法
+func equal(a, b reflect.Value) bool {
+	if m, ok := a.Type().MethodByName("Equal"); ok && m.Type.NumIn() == 2 && m.Type.In(1) == a.Type() &&
+		m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Bool {
+		return m.Func.Call([]reflect.Value{a, b})[0].Bool()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func iface(v reflect.Value) any {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
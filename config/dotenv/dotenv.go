@@ -0,0 +1,412 @@
+package dotenv
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hxnas/pkg/config"
+	"github.com/hxnas/pkg/sys"
+)
+
+const _TAG_ENV = "env"
+
+// Parse 解析 .env 内容，按首次出现的顺序返回 KEY=VALUE 列表，重复的键取最后一次的值
+//
+//	支持的语法：
+//	  # 注释                          整行注释
+//	  export KEY=value                export 前缀
+//	  KEY=value # 注释                未加引号的值去除首尾空白与行尾注释
+//	  KEY='literal ${X}'              单引号内容原样保留，可跨行
+//	  KEY="line1\nline2 ${X}"          双引号支持 \n \t \r \" \\ \$ 转义，可跨行
+//	未加引号与双引号的值中 ${VAR}、${VAR:-default}、${VAR:?error} 引用会被展开，
+//	优先使用文件中已定义的变量，其次使用 lookup（为空时为进程环境变量），都未设置且没有默认值时原样保留。
+func Parse(data []byte, lookup config.LookupEnvFunc) (envs []string, err error) {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	var keys []string
+	values := map[string]string{}
+	local := func(k string) (string, bool) {
+		if v, ok := values[k]; ok {
+			return v, true
+		}
+		return lookup(k)
+	}
+
+	p := &parser{data: bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), line: 1}
+	for {
+		var key, value string
+		var ok bool
+		if key, value, ok, err = p.next(local); err != nil || !ok {
+			break
+		}
+
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+
+	if err != nil {
+		return
+	}
+
+	envs = make([]string, len(keys))
+	for i, k := range keys {
+		envs[i] = k + "=" + values[k]
+	}
+	return
+}
+
+type parser struct {
+	data []byte
+	pos  int
+	line int
+}
+
+// next 读取下一个赋值，没有更多内容时 ok 为 false
+func (p *parser) next(lookup config.LookupEnvFunc) (key, value string, ok bool, err error) {
+	for p.pos < len(p.data) {
+		line := p.line
+		raw := p.readLine()
+
+		s := strings.TrimSpace(raw)
+		if s == "" || s[0] == '#' {
+			continue
+		}
+
+		if rest, found := strings.CutPrefix(s, "export"); found && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			s = strings.TrimSpace(rest)
+		}
+
+		k, v, found := strings.Cut(s, "=")
+		if key = strings.TrimSpace(k); !found || !validKey(key) {
+			err = fmt.Errorf("line %d: invalid assignment: %s", line, s)
+			return
+		}
+
+		if value, err = p.value(strings.TrimLeft(v, " \t"), lookup); err != nil {
+			err = fmt.Errorf("line %d: %s: %w", line, key, err)
+			return
+		}
+		ok = true
+		return
+	}
+	return
+}
+
+func (p *parser) readLine() string {
+	start := p.pos
+	if i := bytes.IndexByte(p.data[start:], '\n'); i >= 0 {
+		p.pos = start + i + 1
+		p.line++
+		return string(p.data[start : start+i])
+	}
+	p.pos = len(p.data)
+	return string(p.data[start:])
+}
+
+// value 解析赋值号右侧的内容，引号未闭合时继续读取后续行
+func (p *parser) value(s string, lookup config.LookupEnvFunc) (value string, err error) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = s[:i]
+		}
+		return expand(strings.TrimSpace(s), lookup)
+	}
+
+	quote := s[0]
+	body := s[1:]
+	for {
+		if end := closingQuote(body, quote); end >= 0 {
+			if rest := strings.TrimSpace(body[end+1:]); rest != "" && rest[0] != '#' {
+				err = fmt.Errorf("unexpected content after closing quote: %s", rest)
+				return
+			}
+			body = body[:end]
+			break
+		}
+
+		if p.pos >= len(p.data) {
+			err = fmt.Errorf("unterminated %c quote", quote)
+			return
+		}
+		body += "\n" + p.readLine()
+	}
+
+	if quote == '\'' {
+		return body, nil
+	}
+	return expand(unescape(body), lookup)
+}
+
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape 处理双引号内的转义，\${ 转换为 config.Expand 的字面量写法 $${
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '$':
+			if b.WriteByte('$'); i+1 < len(s) && s[i+1] == '{' {
+				b.WriteByte('$')
+			}
+		case '"', '\\':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func expand(s string, lookup config.LookupEnvFunc) (string, error) {
+	out, err := config.Expand([]byte(s), lookup)
+	return string(out), err
+}
+
+func validKey(k string) bool {
+	if k == "" {
+		return false
+	}
+	for i, r := range k {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '.' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Unmarshal 解码 .env 内容到 v
+//
+//	v 可以是 *sys.Env（追加所有变量）、*map[string]string，
+//	或结构体指针（按 env 标签赋值，标签可用逗号分隔多个变量名，取第一个存在的）。
+//	变量引用通过 config.SetEnv 设置的来源查找。
+func Unmarshal(v any) config.ReadFunc {
+	return func(data []byte) (err error) {
+		envs, err := Parse(data, config.LookupEnv)
+		if err != nil {
+			return
+		}
+		return assign(v, envs)
+	}
+}
+
+// Load 读取 .env 文件并追加到 env，env 为空时创建新的 sys.Env
+func Load(env *sys.Env, paths ...string) (*sys.Env, error) {
+	if env == nil {
+		env = sys.NewEnv()
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return env, err
+		}
+
+		envs, err := Parse(data, func(k string) (string, bool) {
+			if s, ok := env.Lookup(k); ok {
+				return s, ok
+			}
+			return config.LookupEnv(k)
+		})
+		if err != nil {
+			return env, fmt.Errorf("%s: %w", path, err)
+		}
+		env.Append(envs...)
+	}
+	return env, nil
+}
+
+func assign(v any, envs []string) error {
+	switch t := v.(type) {
+	case *sys.Env:
+		t.Append(envs...)
+		return nil
+	case *map[string]string:
+		if *t == nil {
+			*t = make(map[string]string, len(envs))
+		}
+		for _, it := range envs {
+			k, s, _ := strings.Cut(it, "=")
+			(*t)[k] = s
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dotenv: cannot decode into %T", v)
+	}
+
+	values := make(map[string]string, len(envs))
+	for _, it := range envs {
+		k, s, _ := strings.Cut(it, "=")
+		values[k] = s
+	}
+	_, err := setStruct(rv.Elem(), values)
+	return err
+}
+
+// setStruct 按 env 标签为结构体字段赋值，没有 env 标签的结构体字段递归处理，set 表示是否有字段被赋值
+func setStruct(rv reflect.Value, values map[string]string) (set bool, err error) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		fv := rv.Field(i)
+		tag := strings.TrimSpace(f.Tag.Get(_TAG_ENV))
+		if tag == "-" {
+			continue
+		}
+
+		if tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct || ft == reflect.TypeOf(time.Time{}) {
+				continue
+			}
+
+			sv := fv
+			if fv.Kind() == reflect.Pointer {
+				if sv = reflect.New(ft); !fv.IsNil() {
+					sv.Elem().Set(fv.Elem())
+				}
+				sv = sv.Elem()
+			}
+
+			var ok bool
+			if ok, err = setStruct(sv, values); err != nil {
+				return
+			}
+			if ok && fv.Kind() == reflect.Pointer {
+				fv.Set(sv.Addr())
+			}
+			set = set || ok
+			continue
+		}
+
+		for _, k := range strings.FieldsFunc(tag, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if s, ok := values[k]; ok {
+				if err = setValue(fv, s); err != nil {
+					err = fmt.Errorf("%s: %w", k, err)
+					return
+				}
+				set = true
+				break
+			}
+		}
+	}
+	return
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(v reflect.Value, s string) (err error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), s)
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == durationType {
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil {
+			v.SetInt(int64(d))
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 0, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 0, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(n)
+		}
+	case reflect.Slice:
+		var items []string
+		if s = strings.TrimSpace(s); s != "" {
+			items = strings.Split(s, ",")
+		}
+		sv := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, it := range items {
+			if err = setValue(sv.Index(i), strings.TrimSpace(it)); err != nil {
+				return
+			}
+		}
+		v.Set(sv)
+	default:
+		err = fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return
+}
+
+func init() {
+	config.Registry("dotenv", Unmarshal, ".env")
+}
@@ -0,0 +1,86 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hxnas/pkg/config"
+	"github.com/hxnas/pkg/sys"
+)
+
+const testData = `# comment
+export HOST=nas.local
+PORT = 8080 # inline comment
+URL="http://${HOST}:${PORT}/"
+LITERAL='${HOST} stays'
+ESCAPED="a\"b\tc \${HOST} \$5"
+CERT="-----BEGIN-----
+line
+-----END-----"
+TIMEOUT=${MISSING:-5s}
+EMPTY=
+PORT=9090
+`
+
+func TestParse(t *testing.T) {
+	envs, err := Parse([]byte(testData), func(string) (string, bool) { return "", false })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"HOST=nas.local",
+		"PORT=9090",
+		"URL=http://nas.local:8080/",
+		"LITERAL=${HOST} stays",
+		"ESCAPED=a\"b\tc ${HOST} $5",
+		"CERT=-----BEGIN-----\nline\n-----END-----",
+		"TIMEOUT=5s",
+		"EMPTY=",
+	}
+	if !reflect.DeepEqual(envs, want) {
+		t.Fatalf("got %q, want %q", envs, want)
+	}
+
+	for _, bad := range []string{"1KEY=x", "NOEQUALS", `KEY="open`, `KEY="a" b`} {
+		if _, err = Parse([]byte(bad), nil); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type db struct {
+		Host string `env:"DB_HOST,HOST"`
+	}
+	var cfg struct {
+		Port    int           `env:"PORT"`
+		Timeout time.Duration `env:"TIMEOUT"`
+		Cert    config.Secret `env:"CERT"`
+		DB      *db
+		Skip    *db `env:"-"`
+	}
+	if err := config.Decode(path, &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Port != 9090 || cfg.Timeout != 5*time.Second || cfg.DB == nil || cfg.DB.Host != "nas.local" || cfg.Skip != nil {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	env := sys.NewEnv()
+	if err := config.Decode(path, env); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := env.Lookup("URL"); s != "http://nas.local:8080/" {
+		t.Fatalf("unexpected URL: %q", s)
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/hxnas/pkg/config/dotenv
+
+go 1.22.3
+
+replace (
+	github.com/hxnas/pkg/config => ../
+	github.com/hxnas/pkg/lod => ../../lod
+	github.com/hxnas/pkg/sys => ../../sys
+)
+
+require (
+	github.com/hxnas/pkg/config v0.0.0-00010101000000-000000000000
+	github.com/hxnas/pkg/sys v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/hxnas/pkg/lod v0.0.0-00010101000000-000000000000 // indirect
+	github.com/moby/sys/mount v0.3.3 // indirect
+	github.com/moby/sys/mountinfo v0.7.1 // indirect
+	github.com/moby/sys/symlink v0.2.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/xerrors v0.0.0-20231012003039-104605ab7028 // indirect
+)
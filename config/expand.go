@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LookupEnvFunc 查找环境变量，与 os.LookupEnv 签名一致，sys.Env.Lookup 也满足该签名
+type LookupEnvFunc = func(key string) (value string, ok bool)
+
+// Expand 展开 data 中的环境变量引用
+//
+//	支持 ${VAR}、${VAR:-default}（未设置或为空时使用默认值）与 ${VAR:?error}（未设置或为空时报错），
+//	未设置且没有默认值的 ${VAR} 原样保留，$${ 表示字面量 ${。所有 :? 引起的错误会一并返回。
+func Expand(data []byte, lookup LookupEnvFunc) (out []byte, err error) {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	if !bytes.Contains(data, []byte("${")) {
+		return data, nil
+	}
+
+	var errs []error
+	out = expand(data, lookup, &errs)
+	err = errors.Join(errs...)
+	return
+}
+
+func expand(data []byte, lookup LookupEnvFunc, errs *[]error) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] != '$' || i+1 >= len(data) {
+			out = append(out, data[i])
+			continue
+		}
+
+		if data[i+1] == '$' && i+2 < len(data) && data[i+2] == '{' {
+			out = append(out, '$', '{')
+			i += 2
+			continue
+		}
+
+		if data[i+1] != '{' {
+			out = append(out, data[i])
+			continue
+		}
+
+		end := closingBrace(data, i+2)
+		if end < 0 {
+			out = append(out, data[i:]...)
+			break
+		}
+
+		out = append(out, expandVar(data[i+2:end], lookup, errs)...)
+		i = end
+	}
+	return out
+}
+
+func expandVar(expr []byte, lookup LookupEnvFunc, errs *[]error) []byte {
+	name, op, arg := expr, "", []byte(nil)
+	if i := bytes.IndexByte(expr, ':'); i >= 0 && i+1 < len(expr) && (expr[i+1] == '-' || expr[i+1] == '?') {
+		name, op, arg = expr[:i], string(expr[i:i+2]), expr[i+2:]
+	}
+
+	value, ok := lookup(string(bytes.TrimSpace(name)))
+	if value != "" {
+		return []byte(value)
+	}
+
+	switch op {
+	case ":-":
+		return expand(arg, lookup, errs)
+	case ":?":
+		msg := string(expand(arg, lookup, errs))
+		if msg == "" {
+			msg = "required but not set"
+		}
+		*errs = append(*errs, fmt.Errorf("%s: %s", bytes.TrimSpace(name), msg))
+	default:
+		if !ok {
+			return append(append([]byte("${"), expr...), '}')
+		}
+	}
+	return nil
+}
+
+func closingBrace(data []byte, start int) int {
+	depth := 1
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,10 @@
+module github.com/hxnas/pkg/config/hcl
+
+go 1.22.3
+
+replace github.com/hxnas/pkg/config => ../
+
+require (
+	github.com/hashicorp/hcl v1.0.0
+	github.com/hxnas/pkg/config v0.0.0-00010101000000-000000000000
+)
@@ -0,0 +1,17 @@
+package hcl
+
+import (
+	"github.com/hashicorp/hcl"
+	"github.com/hxnas/pkg/config"
+)
+
+// Unmarshal 解码 HCL（v1 语法），字段名通过 hcl 标签指定
+//
+//	HCL 无法从结构体生成，因此只注册解码器。
+func Unmarshal(v any) config.ReadFunc {
+	return func(data []byte) error { return hcl.Unmarshal(data, v) }
+}
+
+func init() {
+	config.Registry("hcl", Unmarshal, ".hcl")
+}
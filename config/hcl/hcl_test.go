@@ -0,0 +1,64 @@
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hxnas/pkg/config"
+)
+
+type testConfig struct {
+	Name   string            `hcl:"name"`
+	Port   int               `hcl:"port"`
+	Mounts []string          `hcl:"mounts"`
+	Labels map[string]string `hcl:"labels"`
+	DB     struct {
+		Host string `hcl:"host"`
+	} `hcl:"db"`
+}
+
+const testData = `
+name   = "nas"
+port   = 8080
+mounts = ["/data", "/backup"]
+labels { zone = "a" }
+
+db {
+  host = "db.local"
+}
+`
+
+func TestUnmarshal(t *testing.T) {
+	var v testConfig
+	if err := Unmarshal(&v)([]byte(testData)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "nas" || v.Port != 8080 || len(v.Mounts) != 2 || v.Labels["zone"] != "a" || v.DB.Host != "db.local" {
+		t.Fatalf("unexpected value %+v", v)
+	}
+
+	if err := Unmarshal(&v)([]byte(`port = [`)); err == nil {
+		t.Fatal("expected syntax error")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.hcl")
+	if err := os.WriteFile(path, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var v testConfig
+	if err := config.Decode(path, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Port != 8080 || v.DB.Host != "db.local" {
+		t.Fatalf("unexpected value %+v", v)
+	}
+
+	// 只注册了解码器
+	if err := config.Encode(filepath.Join(t.TempDir(), "out.hcl"), &v); err == nil {
+		t.Fatal("expected error encoding hcl")
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// includeKeys 顶层的 include 指令键，在文档解析为通用结构后识别：
+//
+//	yaml:  include: conf.d/*.yaml
+//	json:  "$import": ["conf.d/*.json", "local.json"]
+//	ini:   include = conf.d/*.ini, local.ini
+//
+// 目标结构体声明了同名字段时不作为指令。
+var includeKeys = []string{"include", "$import"}
+
+// takeIncludes 取出并移除 tree 中顶层的 include 指令，返回引入的文件模式
+func takeIncludes(tree map[string]any, t reflect.Type, tag string) (patterns []string, err error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for _, key := range includeKeys {
+		value, ok := tree[key]
+		if !ok || t != nil && t.Kind() == reflect.Struct && hasKey(t, tag, key) {
+			continue
+		}
+		delete(tree, key)
+
+		switch x := value.(type) {
+		case string:
+			for _, s := range strings.Split(x, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					patterns = append(patterns, s)
+				}
+			}
+		case []any:
+			for _, item := range x {
+				s, ok := item.(string)
+				if !ok {
+					err = fmt.Errorf("invalid %s: %v", key, value)
+					return
+				}
+				patterns = append(patterns, s)
+			}
+		case nil:
+		default:
+			err = fmt.Errorf("invalid %s: %v", key, value)
+			return
+		}
+	}
+	return
+}
+
+// hasKey 判断结构体是否声明了键名为 key 的字段
+func hasKey(t reflect.Type, tag, key string) bool {
+	_, ok := fieldType(structKeys(t, tag), key, tag)
+	return ok
+}
+
+// include 按顺序解码 patterns 匹配的文件并合并到 v，相对路径以 d 所在目录为基准
+//
+//	被引入文件的格式由扩展名决定，无法识别时沿用引入方的格式。
+//	被引入文件的通用结构同时合并到 tree，返回合并后的结果，任一文件的通用结构无法获得时返回 nil。
+func (f *Codec) include(d document, patterns []string, v any, tree map[string]any, chain []string, st *decodeState) (_ map[string]any, err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		err = fmt.Errorf("include into non-pointer %T", v)
+		return
+	}
+
+	local, ok := f.localPath(d.path)
+	if !ok {
+		err = fmt.Errorf("include is only supported in local files: %s", d.path)
+		return
+	}
+
+	abs, err := filepath.Abs(local)
+	if err != nil {
+		return
+	}
+	chain = append(slices.Clip(chain), abs)
+
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(abs), pattern)
+		}
+
+		var matches []string
+		if matches, err = filepath.Glob(pattern); err != nil {
+			err = fmt.Errorf("include %s: %w", pattern, err)
+			return
+		}
+
+		if len(matches) == 0 && !hasMeta(pattern) {
+			matches = []string{pattern}
+		}
+
+		for _, match := range matches {
+			if slices.Contains(chain, match) {
+				err = fmt.Errorf("include cycle: %s -> %s", strings.Join(chain, " -> "), match)
+				return
+			}
+
+			sub, e := f.document(match, st.strict)
+			if e != nil {
+				sub = d
+				sub.path = match
+			}
+
+			layer := reflect.New(rv.Type().Elem())
+			var t map[string]any
+			if t, err = f.decode(sub, layer.Interface(), chain, st); err != nil {
+				err = fmt.Errorf("include %s: %w", match, err)
+				return
+			}
+
+			mergeLayer(rv.Elem(), layer.Elem(), t, sub.format)
+			if tree != nil && t != nil {
+				mergeTree(tree, t)
+			} else {
+				tree = nil
+			}
+		}
+	}
+	return tree, nil
+}
+
+func hasMeta(path string) bool { return strings.ContainsAny(path, `*?[\`) }
@@ -1,14 +1,64 @@
 package ini
 
 import (
+	"bytes"
+
 	"github.com/hxnas/pkg/config"
 	"gopkg.in/ini.v1"
 )
 
+// Unmarshal 解码到结构体，或以 *map[string]any 接收通用结构（分区为嵌套的 map）
 func Unmarshal(v any) config.ReadFunc {
-	return func(data []byte) error { return ini.MapTo(v, data) }
+	return func(data []byte) (err error) {
+		if m, ok := v.(*map[string]any); ok {
+			*m, _, err = loadTree(data)
+			return
+		}
+		return ini.MapTo(v, data)
+	}
+}
+
+// loadTree 将 ini 读取为通用结构，默认分区的键位于顶层，同时返回重复的键
+func loadTree(data []byte) (tree map[string]any, dups []config.KeyError, err error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{AllowShadows: true}, data)
+	if err != nil {
+		return
+	}
+
+	tree = map[string]any{}
+	for _, sec := range cfg.Sections() {
+		m, path := tree, ""
+		if name := sec.Name(); name != ini.DefaultSection {
+			m, path = map[string]any{}, name+"."
+			tree[name] = m
+		}
+
+		for _, key := range sec.Keys() {
+			values := key.ValueWithShadows()
+			if len(values) > 1 {
+				dups = append(dups, config.KeyError{Path: path + key.Name(), Message: "duplicate key"})
+			}
+			m[key.Name()] = values[len(values)-1]
+		}
+	}
+	return
+}
+
+func Marshal(v any) (data []byte, err error) {
+	cfg := ini.Empty()
+	if err = ini.ReflectFrom(cfg, v); err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if _, err = cfg.WriteTo(&buf); err == nil {
+		data = buf.Bytes()
+	}
+	return
 }
 
 func init() {
 	config.Registry("ini", Unmarshal, ".ini")
+	config.RegistryEncoder("ini", Marshal, ".ini")
+	config.RegistryStrict("ini", UnmarshalStrict, ".ini")
 }
@@ -0,0 +1,69 @@
+package ini
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hxnas/pkg/config"
+)
+
+type testConfig struct {
+	Name   string   `ini:"name"`
+	Port   int      `ini:"port"`
+	Mounts []string `ini:"mounts"`
+	DB     struct {
+		Host string `ini:"host"`
+	} `ini:"db"`
+}
+
+func TestMarshal(t *testing.T) {
+	var v testConfig
+	data := "name = nas\nport = 8080\nmounts = /data,/backup\n\n[db]\nhost = db.local\n"
+	if err := Unmarshal(&v)([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "nas" || v.Port != 8080 || len(v.Mounts) != 2 || v.DB.Host != "db.local" {
+		t.Fatalf("unexpected value %+v", v)
+	}
+
+	var tree map[string]any
+	if err := Unmarshal(&tree)([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if db, _ := tree["db"].(map[string]any); tree["port"] != "8080" || db["host"] != "db.local" {
+		t.Fatalf("unexpected tree %v", tree)
+	}
+
+	out, err := Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var back testConfig
+	if err = UnmarshalStrict(&back)(out); err != nil {
+		t.Fatalf("strict decode of %s: %v", out, err)
+	}
+	if !reflect.DeepEqual(back, v) {
+		t.Fatalf("round trip: got %+v, want %+v", back, v)
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	var v testConfig
+	data := "name = nas\nname = box\nnmae = typo\nport = abc\n\n[db]\nhots = x\n"
+	var se *config.StrictError
+	if err := UnmarshalStrict(&v)([]byte(data)); !errors.As(err, &se) {
+		t.Fatalf("expected strict error, got %v", err)
+	}
+
+	want := []string{"db.hots: unknown field", "name: duplicate key", "nmae: unknown field", `port: cannot use "abc" as int`}
+	if len(se.Errors) != len(want) {
+		t.Fatalf("got %v, want %v", se.Errors, want)
+	}
+	for i, ke := range se.Errors {
+		if ke.String() != want[i] {
+			t.Errorf("error %d: got %q, want %q", i, ke, want[i])
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package ini
+
+import (
+	"sort"
+
+	"github.com/hxnas/pkg/config"
+)
+
+// UnmarshalStrict 严格模式解码，一次报告所有未知字段、重复键与无法解析的值
+func UnmarshalStrict(v any) config.ReadFunc {
+	return func(data []byte) (err error) {
+		tree, dups, err := loadTree(data)
+		if err != nil {
+			return
+		}
+
+		if errs := append(dups, config.CheckKeys(tree, v, "ini")...); len(errs) > 0 {
+			sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+			return &config.StrictError{Errors: errs}
+		}
+		return Unmarshal(v)(data)
+	}
+}
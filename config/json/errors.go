@@ -0,0 +1,73 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError 是带有原始文件位置的解码错误
+type SyntaxError struct {
+	Line    int    // 行号，从 1 开始
+	Column  int    // 列号（按字符计），从 1 开始
+	Snippet string // 出错的行及指向出错位置的标记
+	Err     error  // encoding/json 返回的原始错误
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v\n%s", e.Line, e.Column, e.Err, e.Snippet)
+}
+
+func (e *SyntaxError) Unwrap() error { return e.Err }
+
+// positionError 将翻译后数据中的错误偏移映射回原始数据中的行列
+func positionError(data []byte, offsets []int, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	// Offset 为出错时已读取的字节数，出错位置是最后读取的字节
+	pos := len(data)
+	if idx := int(offset) - 1; idx >= 0 && idx < len(offsets) {
+		pos = offsets[idx]
+	} else if idx < 0 && len(offsets) > 0 {
+		pos = offsets[0]
+	}
+
+	if pos > len(data) {
+		pos = len(data)
+	}
+
+	start := bytes.LastIndexByte(data[:pos], '\n') + 1
+	end := len(data)
+	if i := bytes.IndexByte(data[start:], '\n'); i >= 0 {
+		end = start + i
+	}
+
+	line := string(bytes.TrimRight(data[start:end], "\r"))
+	column := utf8.RuneCount(data[start:pos]) + 1
+	caret := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, string(data[start:pos]))
+
+	return &SyntaxError{
+		Line:    bytes.Count(data[:start], []byte("\n")) + 1,
+		Column:  column,
+		Snippet: "  " + line + "\n  " + caret + "^",
+		Err:     err,
+	}
+}
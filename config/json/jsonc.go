@@ -6,14 +6,23 @@ const (
 	jc_SPACE    = 32
 	jc_TAB      = 9
 	jc_NEWLINE  = 10
+	jc_RETURN   = 13
 	jc_ASTERISK = 42
 	jc_SLASH    = 47
 	jc_HASH     = 35
+	jc_COMMA    = 44
+	jc_RBRACE   = 125
+	jc_RBRACKET = 93
 )
 
-func jcTranslate(s []byte) []byte {
+// jcTranslate 去除注释、空白与多余的尾逗号，offsets[i] 为输出第 i 个字节在 s 中的位置
+func jcTranslate(s []byte) (out []byte, offsets []int) {
 	if len(s) <= 2 {
-		return s
+		offsets = make([]int, len(s))
+		for k := range offsets {
+			offsets[k] = k
+		}
+		return s, offsets
 	}
 
 	var (
@@ -22,11 +31,12 @@ func jcTranslate(s []byte) []byte {
 		escaped bool
 	)
 	j := make([]byte, len(s))
+	offsets = make([]int, len(s))
 	comment := &jcCommentData{}
-	for _, ch := range s {
+	for n, ch := range s {
 		if ch == jc_ESCAPE || escaped {
 			if !comment.startted {
-				j[i] = ch
+				j[i], offsets[i] = ch, n
 				i++
 			}
 			escaped = !escaped
@@ -35,7 +45,7 @@ func jcTranslate(s []byte) []byte {
 		if ch == jc_QUOTE && !comment.startted {
 			quote = !quote
 		}
-		if (ch == jc_SPACE || ch == jc_TAB) && !quote {
+		if (ch == jc_SPACE || ch == jc_TAB || ch == jc_RETURN) && !quote {
 			continue
 		}
 		if ch == jc_NEWLINE {
@@ -45,7 +55,7 @@ func jcTranslate(s []byte) []byte {
 			continue
 		}
 		if quote && !comment.startted {
-			j[i] = ch
+			j[i], offsets[i] = ch, n
 			i++
 			continue
 		}
@@ -73,10 +83,13 @@ func jcTranslate(s []byte) []byte {
 			comment.start(ch)
 			continue
 		}
-		j[i] = ch
+		if (ch == jc_RBRACE || ch == jc_RBRACKET) && i > 0 && j[i-1] == jc_COMMA {
+			i--
+		}
+		j[i], offsets[i] = ch, n
 		i++
 	}
-	return j[:i]
+	return j[:i], offsets[:i]
 }
 
 type jcCommentData struct {
@@ -0,0 +1,65 @@
+package json
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hxnas/pkg/config"
+)
+
+func TestUnmarshalJsonc(t *testing.T) {
+	var v struct {
+		Mounts []string `json:"mounts"`
+		Name   string   `json:"name"`
+		Port   int      `json:"port"`
+	}
+
+	data := "{\n  // mounts\n  \"mounts\": [\"/data\", \"/backup\",],\n  /* name */ \"name\": \"nas // a\", # hash\n}"
+	if err := Unmarshal(&v)([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Mounts) != 2 || v.Name != "nas // a" {
+		t.Fatalf("unexpected value %+v", v)
+	}
+
+	data = "{\r\n  \"mounts\": [\"/data\",\r\n  ],\r\n  \"port\": 80, // port\r\n}\r\n"
+	if err := Unmarshal(&v)([]byte(data)); err != nil || len(v.Mounts) != 1 || v.Port != 80 {
+		t.Fatalf("crlf: unexpected value %+v, err %v", v, err)
+	}
+
+	data = "{\n  \"name\": \"nas\",\n  \"port\": 80 \"x\": 1\n}"
+	var se *SyntaxError
+	if err := Unmarshal(&v)([]byte(data)); !errors.As(err, &se) || se.Line != 3 || se.Column != 14 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	var v struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+		DB   struct {
+			Host string `json:"host"`
+		} `json:"db"`
+	}
+
+	data := `{"name": 1, "nmae": "typo", "port": "80", "port": 81, "db": {"hots": "x"}}`
+	var se *config.StrictError
+	if err := UnmarshalStrict(&v)([]byte(data)); !errors.As(err, &se) {
+		t.Fatalf("expected strict error, got %v", err)
+	}
+
+	want := []string{"db.hots: unknown field", "name: cannot use json.Number 1 as string", "nmae: unknown field", "port: duplicate key"}
+	if len(se.Errors) != len(want) {
+		t.Fatalf("got %v, want %v", se.Errors, want)
+	}
+	for i, ke := range se.Errors {
+		if ke.String() != want[i] {
+			t.Errorf("error %d: got %q, want %q", i, ke, want[i])
+		}
+	}
+
+	if err := UnmarshalStrict(&v)([]byte(`{"name": "nas", "port": 80, "db": {"host": "x"}}`)); err != nil || v.DB.Host != "x" {
+		t.Fatalf("unexpected result %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,85 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hxnas/pkg/config"
+)
+
+// UnmarshalStrict 严格模式解码，一次报告所有未知字段、重复键与类型不匹配的键
+func UnmarshalStrict(v any) config.ReadFunc {
+	return func(data []byte) (err error) {
+		translated, offsets := jcTranslate(data)
+
+		dec := json.NewDecoder(bytes.NewReader(translated))
+		dec.UseNumber()
+
+		var dups []config.KeyError
+		tree, err := readTree(dec, "", &dups)
+		if err != nil {
+			return positionError(data, offsets, err)
+		}
+
+		if errs := append(dups, config.CheckKeys(tree, v, "json")...); len(errs) > 0 {
+			sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+			return &config.StrictError{Errors: errs}
+		}
+		return Unmarshal(v)(data)
+	}
+}
+
+// readTree 按 token 读取 JSON 为通用结构，同时记录重复的键
+func readTree(dec *json.Decoder, path string, dups *[]config.KeyError) (node any, err error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		m := map[string]any{}
+		for dec.More() {
+			var kt json.Token
+			if kt, err = dec.Token(); err != nil {
+				return
+			}
+
+			key, _ := kt.(string)
+			kp := key
+			if path != "" {
+				kp = path + "." + key
+			}
+
+			if _, exists := m[key]; exists {
+				*dups = append(*dups, config.KeyError{Path: kp, Message: "duplicate key"})
+			}
+
+			if m[key], err = readTree(dec, kp, dups); err != nil {
+				return
+			}
+		}
+		_, err = dec.Token()
+		node = m
+	case json.Delim('['):
+		var items []any
+		for i := 0; dec.More(); i++ {
+			var item any
+			if item, err = readTree(dec, fmt.Sprintf("%s[%d]", path, i), dups); err != nil {
+				return
+			}
+			items = append(items, item)
+		}
+		_, err = dec.Token()
+		node = items
+	default:
+		node = tok
+	}
+	return
+}
@@ -7,9 +7,24 @@ import (
 )
 
 func Unmarshal(v any) config.ReadFunc {
-	return func(data []byte) error { return json.Unmarshal(jcTranslate(data), v) }
+	return func(data []byte) (err error) {
+		translated, offsets := jcTranslate(data)
+		if err = json.Unmarshal(translated, v); err != nil {
+			err = positionError(data, offsets, err)
+		}
+		return
+	}
+}
+
+func Marshal(v any) (data []byte, err error) {
+	if data, err = json.MarshalIndent(v, "", "  "); err == nil {
+		data = append(data, '\n')
+	}
+	return
 }
 
 func init() {
 	config.Registry("json", Unmarshal, ".json", ".jsonc")
+	config.RegistryStrict("json", UnmarshalStrict, ".json", ".jsonc")
+	config.RegistryEncoder("json", Marshal, ".json", ".jsonc")
 }
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	_TAG_MERGE = "merge"
+
+	mergeAppend = "append"
+)
+
+// merge 将 src 深度合并到 dst，dst 与 src 必须是同一类型
+//
+//	结构体逐字段合并（没有导出字段或实现 encoding.TextUnmarshaler 的结构体整体替换），map 逐键合并，切片默认整体替换，
+//	字段声明 `merge:"append"` 时追加；src 中的零值不会覆盖 dst。
+func merge(dst, src reflect.Value, appendSlice bool) {
+	switch src.Kind() {
+	case reflect.Struct:
+		if isOpaque(src.Type()) {
+			if !src.IsZero() {
+				dst.Set(src)
+			}
+			return
+		}
+		for i, t := 0, src.Type(); i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				merge(dst.Field(i), src.Field(i), GetTag(f.Tag, _TAG_MERGE) == mergeAppend)
+			}
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		for it := src.MapRange(); it.Next(); {
+			sv := it.Value()
+			if dv := dst.MapIndex(it.Key()); dv.IsValid() && isMergeable(sv) {
+				nv := reflect.New(dv.Type()).Elem()
+				nv.Set(dv)
+				merge(nv, sv, appendSlice)
+				sv = nv
+			}
+			dst.SetMapIndex(it.Key(), sv)
+		}
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return
+		}
+		if appendSlice && !dst.IsNil() {
+			dst.Set(reflect.AppendSlice(dst, src))
+		} else {
+			dst.Set(src)
+		}
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if !dst.IsNil() && isMergeable(src.Elem()) {
+			merge(dst.Elem(), src.Elem(), appendSlice)
+		} else {
+			dst.Set(src)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		if se, de := src.Elem(), dst.Elem(); de.IsValid() && se.Type() == de.Type() && se.Kind() == reflect.Map {
+			nv := reflect.MakeMapWithSize(de.Type(), de.Len())
+			merge(nv, de, false)
+			merge(nv, se, appendSlice)
+			dst.Set(nv)
+		} else {
+			dst.Set(src)
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// mergeLayer 将配置层 src 合并到 dst，tree 为该层文档的通用结构，format 为其格式名称
+//
+//	只有 tree 中出现的键会覆盖 dst，值为 false、0 或空字符串时同样覆盖；
+//	tree 为 nil（格式无法解码为通用结构）时退化为 merge，零值不覆盖。
+func mergeLayer(dst, src reflect.Value, tree map[string]any, format string) {
+	if tree == nil {
+		merge(dst, src, false)
+		return
+	}
+	mergePresent(dst, src, tree, format, false)
+}
+
+// mergePresent 按 node 中出现的键将 src 合并到 dst，node 为 src 对应的通用结构
+func mergePresent(dst, src reflect.Value, node any, tag string, appendSlice bool) {
+	switch src.Kind() {
+	case reflect.Struct:
+		m, ok := node.(map[string]any)
+		if !ok || isOpaque(src.Type()) {
+			dst.Set(src)
+			return
+		}
+		for i, t := 0, src.Type(); i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			key, inline, skip := tagKey(f, tag)
+			switch {
+			case skip:
+			case inline:
+				if sv := src.Field(i); sv.Kind() != reflect.Pointer || !sv.IsNil() {
+					mergePresent(dst.Field(i), sv, m, tag, false)
+				}
+			default:
+				if child, ok := treeKey(m, key); ok {
+					mergePresent(dst.Field(i), src.Field(i), child, tag, GetTag(f.Tag, _TAG_MERGE) == mergeAppend)
+				}
+			}
+		}
+	case reflect.Map:
+		m, ok := node.(map[string]any)
+		if !ok || src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		for it := src.MapRange(); it.Next(); {
+			sv := it.Value()
+			if dv := dst.MapIndex(it.Key()); dv.IsValid() && isMergeable(sv) {
+				nv := reflect.New(dv.Type()).Elem()
+				nv.Set(dv)
+				mergePresent(nv, sv, m[fmt.Sprint(it.Key())], tag, appendSlice)
+				sv = nv
+			}
+			dst.SetMapIndex(it.Key(), sv)
+		}
+	case reflect.Slice:
+		if appendSlice && !dst.IsNil() {
+			dst.Set(reflect.AppendSlice(dst, src))
+		} else {
+			dst.Set(src)
+		}
+	case reflect.Pointer:
+		if !src.IsNil() && !dst.IsNil() && isMergeable(src.Elem()) {
+			mergePresent(dst.Elem(), src.Elem(), node, tag, appendSlice)
+		} else {
+			dst.Set(src)
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(src)
+		} else {
+			merge(dst, src, appendSlice)
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// mergeTree 将通用结构 src 深度合并到 dst
+func mergeTree(dst, src map[string]any) {
+	for k, sv := range src {
+		if sm, ok := sv.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeTree(dm, sm)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+}
+
+// treeKey 查找通用结构中的键，找不到时忽略大小写再查找一次
+func treeKey(m map[string]any, key string) (v any, ok bool) {
+	if v, ok = m[key]; ok {
+		return
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return
+}
+
+// tagKey 返回结构体字段在 tag 格式配置文件中的键名
+//
+//	inline 表示需要展开的匿名或声明 inline 选项的结构体字段（yaml 只展开声明 inline 的字段），
+//	skip 表示标签为 - 的忽略字段。
+func tagKey(f reflect.StructField, tag string) (key string, inline, skip bool) {
+	name, opts, _ := strings.Cut(GetTag(f.Tag, tag), ",")
+	if name == "-" {
+		return "", false, true
+	}
+
+	ft := f.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+
+	if ft.Kind() == reflect.Struct && (strings.Contains(opts, "inline") || f.Anonymous && name == "" && tag != "yaml") {
+		return "", true, false
+	}
+
+	if name == "" {
+		name = fieldKey(f.Name, tag)
+	}
+	return name, false, false
+}
+
+func isMergeable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		return true
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil() && isMergeable(v.Elem())
+	default:
+		return false
+	}
+}
+
+// clone 深拷贝 v，返回的值与 v 不共享任何可变内存
+func clone(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Struct:
+		out.Set(v)
+		for i, t := 0, v.Type(); i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				out.Field(i).Set(clone(v.Field(i)))
+			}
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			for it := v.MapRange(); it.Next(); {
+				out.SetMapIndex(it.Key(), clone(it.Value()))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				out.Index(i).Set(clone(v.Index(i)))
+			}
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			out.Set(reflect.New(v.Type().Elem()))
+			out.Elem().Set(clone(v.Elem()))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(clone(v.Elem()))
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
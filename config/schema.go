@@ -0,0 +1,256 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	_TAG_USAGE      = "usage"
+	_TAG_DEFAULT    = "default"
+	_TAG_DEPRECATED = "deprecated"
+
+	schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// JSONSchema 根据配置结构体生成 JSON Schema 文档
+//
+//	tagName 为字段命名使用的标签（json、yaml 等），为空时使用 json。
+//	usage 标签作为字段描述，default 标签作为默认值，validate 标签转换为 required、
+//	minimum/maximum、minLength/maxLength、minItems/maxItems 与 enum 约束。
+//	结构体不允许未声明的键，顶层额外允许 $schema 与 include 指令（include、$import）。
+//	encoding/json 只能从整数（纳秒）解码 time.Duration，因此 json 中 Duration 为 integer，其它格式为时长字符串。
+func JSONSchema(v any, tagName string) ([]byte, error) {
+	if tagName == "" {
+		tagName = "json"
+	}
+
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	s := (&schemaBuilder{tag: tagName, visiting: map[reflect.Type]bool{}}).build(t)
+	if properties, ok := s["properties"].(map[string]any); ok {
+		// 编辑器使用的 $schema 与 include 指令，结构体声明了同名字段时保留字段的定义
+		reserved := map[string]any{"$schema": map[string]any{"type": "string"}}
+		for _, key := range includeKeys {
+			reserved[key] = map[string]any{"anyOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			}}
+		}
+		for key, rs := range reserved {
+			if _, declared := properties[key]; !declared {
+				properties[key] = rs
+			}
+		}
+	}
+	s["$schema"] = schemaDraft
+	if t != nil && t.Name() != "" {
+		s["title"] = t.Name()
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+type schemaBuilder struct {
+	tag      string
+	visiting map[reflect.Type]bool
+}
+
+func (b *schemaBuilder) build(t reflect.Type) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+
+	switch {
+	case t == durationType && b.tag == "json":
+		return map[string]any{"type": "integer"}
+	case t == durationType:
+		return map[string]any{"type": "string", "pattern": `^(\d+d)?([0-9.]+(ns|us|µs|ms|s|m|h))*$`}
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == secretType:
+		return map[string]any{"type": "string", "writeOnly": true}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch kind := t.Kind(); {
+	case kind == reflect.Pointer:
+		return b.build(t.Elem())
+	case kind == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case kind == reflect.Float32, kind == reflect.Float64:
+		return map[string]any{"type": "number"}
+	case kind == reflect.String:
+		return map[string]any{"type": "string"}
+	case (kind == reflect.Slice || kind == reflect.Array) && t.Elem().Kind() == reflect.Uint8:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case kind == reflect.Slice, kind == reflect.Array:
+		return map[string]any{"type": "array", "items": b.build(t.Elem())}
+	case kind == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.build(t.Elem())}
+	case kind == reflect.Struct:
+		if b.visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		b.visiting[t] = true
+		defer delete(b.visiting, t)
+
+		properties, required := map[string]any{}, []string{}
+		b.fields(t, properties, &required)
+
+		s := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	default:
+		return map[string]any{}
+	}
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, inline, skip := tagKey(f, b.tag)
+		if skip {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if inline {
+			b.fields(ft, properties, required)
+			continue
+		}
+
+		s := b.build(f.Type)
+		if usage := GetTag(f.Tag, _TAG_USAGE); usage != "" {
+			s["description"] = usage
+		}
+		if def := GetTag(f.Tag, _TAG_DEFAULT); def != "" {
+			s["default"] = b.value(ft, def)
+		}
+		if GetTag(f.Tag, _TAG_DEPRECATED) != "" {
+			s["deprecated"] = true
+		}
+		if IsSecret(f) {
+			s["writeOnly"] = true
+		}
+		if b.rules(ft, GetTag(f.Tag, _TAG_VALIDATE), s) {
+			*required = append(*required, name)
+		}
+
+		properties[name] = s
+	}
+}
+
+// rules 将 validate 标签转换为 schema 约束，返回字段是否必填
+func (b *schemaBuilder) rules(t reflect.Type, tag string, s map[string]any) (required bool) {
+	if tag == "" || tag == "-" {
+		return
+	}
+
+	for _, rule := range ParseRules(tag) {
+		name, arg := rule.Name, rule.Arg
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			if t == durationType {
+				continue
+			}
+
+			key := map[string]string{"min": "minimum", "max": "maximum"}[name]
+			switch t.Kind() {
+			case reflect.String:
+				key = name + "Length"
+			case reflect.Slice, reflect.Array:
+				key = name + "Items"
+			case reflect.Map:
+				key = name + "Properties"
+			}
+
+			if n, err := strconv.ParseFloat(arg, 64); err == nil {
+				s[key] = n
+			}
+		case "oneof":
+			var enum []any
+			for _, it := range strings.Fields(arg) {
+				enum = append(enum, b.value(t, it))
+			}
+			s["enum"] = enum
+		}
+	}
+	return
+}
+
+// value 将标签中的字符串按字段类型转换为 JSON 值
+func (b *schemaBuilder) value(t reflect.Type, s string) any {
+	if t == durationType && b.tag == "json" {
+		if d, err := time.ParseDuration(s); err == nil {
+			return int64(d)
+		}
+	}
+	if t == durationType || t == timeType {
+		return s
+	}
+
+	switch kind := t.Kind(); {
+	case kind == reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+			return n
+		}
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		if n, err := strconv.ParseUint(s, 0, 64); err == nil {
+			return n
+		}
+	case kind == reflect.Float32, kind == reflect.Float64:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	case kind == reflect.Slice, kind == reflect.Array:
+		var items []any
+		for _, it := range strings.Split(s, ",") {
+			items = append(items, b.value(t.Elem(), strings.TrimSpace(it)))
+		}
+		return items
+	}
+	return s
+}
+
+// fieldKey 返回未声明标签时字段在配置文件中的键名
+func fieldKey(name, tag string) string {
+	switch tag {
+	case "yaml":
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	_TAG_SECRET = "secret"
+
+	secretMask = "******"
+)
+
+// Secret 敏感字符串，格式化输出与日志中显示为 ******，使用 string(s) 获取原始值
+//
+//	解码后 Secret 字段与带有 `secret:"true"` 标签的字符串字段中的引用会被解析，如：
+//	  file:/run/secrets/db   读取文件内容（去除首尾空白）
+//	  env:DB_PASS            读取环境变量
+//	  base64:cGFzcw==        base64 解码
+type Secret string
+
+func (s Secret) String() string { return mask(string(s)) }
+
+func (s Secret) GoString() string { return `"` + s.String() + `"` }
+
+func (s Secret) LogValue() slog.Value { return slog.StringValue(s.String()) }
+
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	return secretMask
+}
+
+// SecretResolver 将去掉前缀后的引用解析为实际值
+type SecretResolver func(ref string) (value string, err error)
+
+func RegistryResolver(scheme string, resolver SecretResolver) {
+	decoder.RegisterResolver(scheme, resolver)
+}
+
+func (f *Codec) RegisterResolver(scheme string, resolver SecretResolver) {
+	if f.resolvers == nil {
+		f.resolvers = make(map[string]SecretResolver)
+	}
+	f.resolvers[scheme] = resolver
+}
+
+// ResolveSecrets 使用全局解码器解析 v 中的敏感字段引用
+func ResolveSecrets(v any) error { return decoder.ResolveSecrets(v) }
+
+// ResolveSecrets 解析 v 中 Secret 字段与带 secret 标签的字符串字段中的引用，
+// 没有可识别前缀的值保持不变
+func (f *Codec) ResolveSecrets(v any) error {
+	var errs []error
+	f.walkSecrets("", reflect.ValueOf(v), false, func(path string, sv reflect.Value) {
+		scheme, ref, ok := strings.Cut(sv.String(), ":")
+		if !ok {
+			return
+		}
+
+		resolver := f.resolver(scheme)
+		if resolver == nil {
+			return
+		}
+
+		s, err := resolver(ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: resolve %s secret: %w", path, scheme, err))
+			return
+		}
+		sv.SetString(s)
+	})
+	return errors.Join(errs...)
+}
+
+func (f *Codec) resolver(scheme string) SecretResolver {
+	if r, ok := f.resolvers[scheme]; ok {
+		return r
+	}
+
+	switch scheme {
+	case "file":
+		return func(ref string) (string, error) {
+			data, err := os.ReadFile(ref)
+			return strings.TrimSpace(string(data)), err
+		}
+	case "env":
+		return func(ref string) (string, error) {
+			if s, ok := f.LookupEnv(ref); ok {
+				return s, nil
+			}
+			return "", fmt.Errorf("env %s is not set", ref)
+		}
+	case "base64":
+		return func(ref string) (string, error) {
+			data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ref))
+			return string(data), err
+		}
+	}
+	return nil
+}
+
+// Redact 返回 v 的深拷贝，其中 Secret 字段与带 secret 标签的非空字符串字段被替换为 ******
+func Redact(v any) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return v
+	}
+
+	out := clone(rv)
+	decoder.walkSecrets("", out, false, func(_ string, sv reflect.Value) { sv.SetString(mask(sv.String())) })
+	return out.Interface()
+}
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// walkSecrets 遍历 v 中所有可设置的敏感字符串
+func (f *Codec) walkSecrets(path string, v reflect.Value, tagged bool, fn func(path string, sv reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			f.walkSecrets(path, v.Elem(), tagged, fn)
+		}
+	case reflect.Struct:
+		for i, t := 0, v.Type(); i < t.NumField(); i++ {
+			if sf := t.Field(i); sf.IsExported() {
+				f.walkSecrets(joinPath(path, sf.Name), v.Field(i), IsSecret(sf), fn)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.walkSecrets(fmt.Sprintf("%s[%d]", path, i), v.Index(i), tagged, fn)
+		}
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			mv := reflect.New(it.Value().Type()).Elem()
+			mv.Set(it.Value())
+			f.walkSecrets(fmt.Sprintf("%s[%v]", path, it.Key()), mv, tagged, fn)
+			v.SetMapIndex(it.Key(), mv)
+		}
+	case reflect.String:
+		if (tagged || v.Type() == secretType) && v.CanSet() {
+			fn(path, v)
+		}
+	}
+}
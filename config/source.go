@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source 按 scheme 读取配置源的原始内容，location 为去掉 scheme 前缀后的部分
+//
+//	配置源的完整格式为 [format:][scheme:]location，例如：
+//	  /etc/app.yaml                  本地文件
+//	  file:///etc/app.yaml           本地文件
+//	  yaml:-、yaml:stdin             标准输入
+//	  https://host/app.yaml          http(s) 地址
+//	  yaml:env:APP_CONFIG            环境变量的内容，通过 SetEnv 设置的来源读取
+//	  fs:defaults/app.yaml           文件系统，fs 不是内置的 scheme，需先通过 RegistrySource("fs", FS(embedFS)) 注册
+//	内容不存在时应返回包装了 fs.ErrNotExist 的错误，以便可选配置层跳过。
+type Source interface {
+	Open(location string) (io.ReadCloser, error)
+}
+
+type SourceFunc func(location string) (io.ReadCloser, error)
+
+func (fn SourceFunc) Open(location string) (io.ReadCloser, error) { return fn(location) }
+
+const (
+	schemeFile  = "file"
+	schemeStdin = "stdin"
+	schemeEnv   = "env"
+)
+
+var builtinSources = map[string]Source{
+	schemeFile:  SourceFunc(openFile),
+	schemeStdin: SourceFunc(openStdin),
+	"http":      HTTP("http", nil),
+	"https":     HTTP("https", nil),
+}
+
+func RegistrySource(scheme string, src Source) { decoder.RegisterSource(scheme, src) }
+
+func (f *Codec) RegisterSource(scheme string, src Source) {
+	if f.sources == nil {
+		f.sources = make(map[string]Source)
+	}
+	f.sources[strings.ToLower(scheme)] = src
+}
+
+func (f *Codec) source(scheme string) Source {
+	if src, ok := f.sources[scheme]; ok {
+		return src
+	}
+	if scheme == schemeEnv {
+		return Env(f.LookupEnv)
+	}
+	return builtinSources[scheme]
+}
+
+// splitScheme 拆分配置源的 scheme 与 location，没有可识别的 scheme 时视为本地文件
+func (f *Codec) splitScheme(p string) (scheme, location string) {
+	if p == "-" {
+		return schemeStdin, ""
+	}
+
+	// 单字母前缀视为 Windows 盘符
+	if s, l, ok := strings.Cut(p, ":"); ok && len(s) > 1 && f.source(strings.ToLower(s)) != nil {
+		if scheme = strings.ToLower(s); scheme == schemeFile {
+			l = strings.TrimPrefix(l, "//")
+		}
+		return scheme, l
+	}
+
+	if p == schemeStdin {
+		return schemeStdin, ""
+	}
+	return schemeFile, p
+}
+
+// localPath 返回配置源对应的本地文件路径，非本地文件时 ok 为 false
+func (f *Codec) localPath(p string) (path string, ok bool) {
+	scheme, location := f.splitScheme(p)
+	return location, scheme == schemeFile
+}
+
+// sourceExt 返回配置源 location 的扩展名，用于推断格式
+func (f *Codec) sourceExt(p string) string {
+	scheme, location := f.splitScheme(p)
+	switch scheme {
+	case schemeFile:
+		return filepath.Ext(location)
+	case "http", "https":
+		if u, err := url.Parse(scheme + ":" + location); err == nil {
+			return path.Ext(u.Path)
+		}
+	}
+	return path.Ext(location)
+}
+
+func (f *Codec) readSource(p string) (data []byte, err error) {
+	scheme, location := f.splitScheme(p)
+
+	var r io.ReadCloser
+	if r, err = f.source(scheme).Open(location); err != nil {
+		return
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func openFile(location string) (io.ReadCloser, error) { return os.Open(location) }
+
+func openStdin(string) (io.ReadCloser, error) { return io.NopCloser(os.Stdin), nil }
+
+// Env 将环境变量包装为配置源，location 为变量名，lookup 为空时使用进程环境变量
+func Env(lookup LookupEnvFunc) Source {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	return SourceFunc(func(key string) (io.ReadCloser, error) {
+		s, ok := lookup(key)
+		if !ok {
+			return nil, fmt.Errorf("env %s: %w", key, fs.ErrNotExist)
+		}
+		return io.NopCloser(strings.NewReader(s)), nil
+	})
+}
+
+// FS 将文件系统（如 embed.FS）包装为配置源，location 为文件系统内的路径
+func FS(fsys fs.FS) Source {
+	return SourceFunc(func(location string) (io.ReadCloser, error) {
+		return fsys.Open(strings.TrimPrefix(path.Clean("/"+location), "/"))
+	})
+}
+
+// HTTP 创建通过 http(s) 读取配置的配置源，client 为空时使用 10s 超时的默认客户端
+func HTTP(scheme string, client *http.Client) Source {
+	return SourceFunc(func(location string) (io.ReadCloser, error) {
+		c := client
+		if c == nil {
+			c = &http.Client{Timeout: 10 * time.Second}
+		}
+
+		u := scheme + ":" + location
+		resp, err := c.Get(u)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound:
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %w", u, fs.ErrNotExist)
+		case resp.StatusCode >= 300:
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %s", u, resp.Status)
+		}
+		return resp.Body, nil
+	})
+}
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyError 描述严格模式下一个有问题的配置键
+type KeyError struct {
+	Path    string // 配置文件中的键路径，如 db.host、servers[0].port
+	Message string
+}
+
+func (e KeyError) String() string { return e.Path + ": " + e.Message }
+
+// StrictError 严格模式下的解码错误，包含所有未知字段、重复键与类型不匹配的键
+type StrictError struct {
+	Source string
+	Errors []KeyError
+}
+
+func (e *StrictError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, ke := range e.Errors {
+		lines[i] = ke.String()
+	}
+
+	prefix := "strict decode failed"
+	if e.Source != "" {
+		prefix += " " + e.Source
+	}
+	return prefix + ":\n  " + strings.Join(lines, "\n  ")
+}
+
+// Strict 本次解码使用严格模式：未知字段、重复键与类型不匹配均视为错误
+func Strict() DecodeOption { return func(o *decodeOptions) { o.strict = true } }
+
+// SetStrict 指定格式（名称或扩展名）在全局解码器中始终使用严格模式，
+// 按名称指定时同时包含该格式注册严格模式解码函数时的扩展名
+func SetStrict(names ...string) { decoder.SetStrict(names...) }
+
+func RegistryStrict(name string, unmarshal DecodeFunc, exts ...string) {
+	decoder.RegisterStrict(name, unmarshal, exts...)
+}
+
+// RegisterStrict 注册格式的严格模式解码函数
+//
+//	严格模式解码函数应在发现问题时返回 *StrictError，可借助 CheckKeys 比对通用结构与目标类型。
+func (f *Codec) RegisterStrict(name string, decodeFunc DecodeFunc, exts ...string) {
+	f.stricts = register(f.stricts, decodeFunc, name, exts...)
+	f.strictExts = register(f.strictExts, exts, name)
+}
+
+func (f *Codec) SetStrict(names ...string) {
+	for _, name := range names {
+		f.strictFormats = register(f.strictFormats, true, name, f.strictExts[strings.ToLower(name)]...)
+	}
+}
+
+// resolveStrict 查找配置源对应的严格模式解码函数
+func (f *Codec) resolveStrict(source string) (unmarshal DecodeFunc, path string, err error) {
+	if unmarshal, path, err = lookup(f.stricts, source, f.sourceExt); err != nil {
+		err = fmt.Errorf("strict decode is not supported: %s", source)
+	}
+	return
+}
+
+// isStrict 判断配置源的格式是否被设置为始终严格
+func (f *Codec) isStrict(source string) bool {
+	strict, _, _ := lookup(f.strictFormats, source, f.sourceExt)
+	return strict
+}
+
+// CheckKeys 将格式解码得到的通用结构（map[string]any、[]any 与标量）与 v 的类型比对，
+// 返回所有未知字段与类型不匹配的键。tagName 为该格式使用的字段标签。
+func CheckKeys(tree any, v any, tagName string) (errs []KeyError) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil {
+		checkKeys("", tree, t, tagName, &errs)
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func checkKeys(path string, node any, t reflect.Type, tag string, errs *[]KeyError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if node == nil || t.Kind() == reflect.Interface {
+		return
+	}
+
+	mismatch := func() {
+		*errs = append(*errs, KeyError{Path: path, Message: fmt.Sprintf("cannot use %s as %s", describe(node), t)})
+	}
+
+	switch {
+	case t == timeType:
+		if _, ok := node.(time.Time); !ok && !isString(node) {
+			mismatch()
+		}
+		return
+	case t == durationType:
+		if !isString(node) && !isInteger(node) {
+			mismatch()
+		}
+		return
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		if !isString(node) {
+			mismatch()
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := node.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+
+		fields := structKeys(t, tag)
+		for k, child := range m {
+			if ft, ok := fieldType(fields, k, tag); ok {
+				checkKeys(joinPath(path, k), child, ft, tag, errs)
+			} else if path != "" || !slices.Contains(includeKeys, k) {
+				*errs = append(*errs, KeyError{Path: joinPath(path, k), Message: "unknown field"})
+			}
+		}
+	case reflect.Map:
+		m, ok := node.(map[string]any)
+		if !ok {
+			mismatch()
+			return
+		}
+		for k, child := range m {
+			checkKeys(joinPath(path, k), child, t.Elem(), tag, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && isString(node) {
+			return
+		}
+		items, ok := node.([]any)
+		if !ok {
+			if tag == "ini" && isString(node) {
+				return
+			}
+			mismatch()
+			return
+		}
+		for i, child := range items {
+			checkKeys(fmt.Sprintf("%s[%d]", path, i), child, t.Elem(), tag, errs)
+		}
+	case reflect.String:
+		if !isString(node) && (tag != "yaml" || !isScalar(node)) {
+			mismatch()
+		}
+	case reflect.Bool:
+		if _, ok := node.(bool); !ok && !parsable(node, tag, func(s string) error { _, e := strconv.ParseBool(s); return e }) {
+			mismatch()
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInteger(node) && !parsable(node, tag, func(s string) error { _, e := strconv.ParseInt(s, 0, t.Bits()); return e }) {
+			mismatch()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isInteger(node) && !parsable(node, tag, func(s string) error { _, e := strconv.ParseUint(s, 0, t.Bits()); return e }) {
+			mismatch()
+		}
+	case reflect.Float32, reflect.Float64:
+		if !isNumber(node) && !parsable(node, tag, func(s string) error { _, e := strconv.ParseFloat(s, t.Bits()); return e }) {
+			mismatch()
+		}
+	}
+}
+
+// structKeys 返回结构体字段在配置文件中的键名到字段类型的映射，匿名或 inline 结构体字段会被展开
+func structKeys(t reflect.Type, tag string) map[string]reflect.Type {
+	keys := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		key, inline, skip := tagKey(f, tag)
+		switch {
+		case skip:
+		case inline:
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			maps.Copy(keys, structKeys(ft, tag))
+		default:
+			keys[key] = f.Type
+		}
+	}
+	return keys
+}
+
+// fieldType 按格式解码器的规则查找键对应的字段：json、toml 与 hcl 忽略大小写，yaml 与 ini 区分大小写
+func fieldType(fields map[string]reflect.Type, key, tag string) (reflect.Type, bool) {
+	if t, ok := fields[key]; ok {
+		return t, true
+	}
+	if foldCase(tag) {
+		for k, t := range fields {
+			if strings.EqualFold(k, key) {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// foldCase 判断格式的解码器匹配字段时是否忽略大小写
+func foldCase(tag string) bool {
+	switch tag {
+	case "yaml", "ini":
+		return false
+	default:
+		return true
+	}
+}
+
+func isString(node any) bool { _, ok := node.(string); return ok }
+
+func isScalar(node any) bool {
+	switch node.(type) {
+	case map[string]any, []any:
+		return false
+	default:
+		return true
+	}
+}
+
+func isNumber(node any) bool {
+	switch node.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return true
+	default:
+		return false
+	}
+}
+
+func isInteger(node any) bool {
+	switch n := node.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float64:
+		return n == math.Trunc(n)
+	case float32:
+		return float64(n) == math.Trunc(float64(n))
+	case json.Number:
+		_, err := n.Int64()
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// parsable ini 中所有值都是字符串，需要按目标类型尝试解析
+func parsable(node any, tag string, parse func(string) error) bool {
+	s, ok := node.(string)
+	return ok && tag == "ini" && parse(s) == nil
+}
+
+func describe(node any) string {
+	switch n := node.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return strconv.Quote(n)
+	default:
+		return fmt.Sprintf("%T %v", node, node)
+	}
+}
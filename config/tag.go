@@ -0,0 +1,60 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GetTag 返回去除首尾空白的标签值
+func GetTag(tag reflect.StructTag, tagName string) string {
+	return strings.TrimSpace(tag.Get(tagName))
+}
+
+// IsSecret 判断字段是否带有 secret 标签，标签值按 strconv.ParseBool 解析，如 true、1、True
+func IsSecret(f reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(GetTag(f.Tag, _TAG_SECRET))
+	return secret
+}
+
+// SplitTag 拆分列表标签，以 , ; | 或空白分隔，去除各项首尾的 - 与 _ 并忽略空项
+func SplitTag(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return isTagSep(r) || unicode.IsSpace(r) })
+	var x int
+	for _, s := range fields {
+		if s = strings.Trim(s, "-_"); s != "" {
+			fields[x] = s
+			x++
+		}
+	}
+	return fields[:x]
+}
+
+// Rule validate 标签中的一条规则，如 max=65535 的 Name 为 max，Arg 为 65535
+type Rule struct {
+	Name string
+	Arg  string
+}
+
+func (r Rule) String() string {
+	if r.Arg == "" {
+		return r.Name
+	}
+	return r.Name + "=" + r.Arg
+}
+
+// ParseRules 拆分 validate 标签中的规则
+//
+//	规则之间与 SplitTag 一样以 , ; | 分隔，但不以空白分隔，因为 oneof 的参数以空格分隔。
+func ParseRules(tag string) (rules []Rule) {
+	for _, s := range strings.FieldsFunc(tag, isTagSep) {
+		if s = strings.TrimSpace(s); s != "" {
+			name, arg, _ := strings.Cut(s, "=")
+			rules = append(rules, Rule{Name: strings.TrimSpace(name), Arg: strings.TrimSpace(arg)})
+		}
+	}
+	return
+}
+
+func isTagSep(r rune) bool { return r == ',' || r == ';' || r == '|' }
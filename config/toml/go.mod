@@ -0,0 +1,10 @@
+module github.com/hxnas/pkg/config/toml
+
+go 1.22.3
+
+replace github.com/hxnas/pkg/config => ../
+
+require (
+	github.com/hxnas/pkg/config v0.0.0-00010101000000-000000000000
+	github.com/pelletier/go-toml/v2 v2.2.4
+)
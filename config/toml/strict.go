@@ -0,0 +1,21 @@
+package toml
+
+import (
+	"github.com/hxnas/pkg/config"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// UnmarshalStrict 严格模式解码，一次报告所有未知字段与类型不匹配的键，重复键由 toml 解析本身报告
+func UnmarshalStrict(v any) config.ReadFunc {
+	return func(data []byte) (err error) {
+		var tree map[string]any
+		if err = toml.Unmarshal(data, &tree); err != nil {
+			return
+		}
+
+		if errs := config.CheckKeys(tree, v, "toml"); len(errs) > 0 {
+			return &config.StrictError{Errors: errs}
+		}
+		return Unmarshal(v)(data)
+	}
+}
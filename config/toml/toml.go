@@ -0,0 +1,28 @@
+package toml
+
+import (
+	"bytes"
+
+	"github.com/hxnas/pkg/config"
+	"github.com/pelletier/go-toml/v2"
+)
+
+func Unmarshal(v any) config.ReadFunc {
+	return func(data []byte) error { return toml.Unmarshal(data, v) }
+}
+
+func Marshal(v any) (data []byte, err error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.SetIndentTables(true)
+	if err = enc.Encode(v); err == nil {
+		data = buf.Bytes()
+	}
+	return
+}
+
+func init() {
+	config.Registry("toml", Unmarshal, ".toml")
+	config.RegistryEncoder("toml", Marshal, ".toml")
+	config.RegistryStrict("toml", UnmarshalStrict, ".toml")
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const _TAG_VALIDATE = "validate"
+
+// Violation 描述一条未通过的校验规则
+type Violation struct {
+	Path    string // 字段路径，如 DB.Port、Users[0].Name
+	Rule    string // 规则，如 required、min=1
+	Message string
+	Source  string // 字段值来自的配置源
+}
+
+func (v Violation) String() string {
+	s := v.Path + ": " + v.Message
+	if v.Source != "" {
+		s += " (" + v.Source + ")"
+	}
+	return s
+}
+
+// ValidationError 包含所有未通过校验的字段
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return "config validation failed:\n  " + strings.Join(lines, "\n  ")
+}
+
+// Validate 按 validate 标签校验结构体，返回所有不满足规则的字段
+//
+//	支持的规则以逗号分隔：
+//	  required   值不能为零值
+//	  min=N      数值不小于 N，字符串、切片、map 长度不小于 N，time.Duration 可写作 min=1s
+//	  max=N      同 min，上限
+//	  oneof=a b  值必须为空格分隔的候选值之一
+//	非 required 的字段为零值时跳过其它规则。
+func Validate(v any) error { return validate(v, nil, "") }
+
+// validate 校验 v，origins 记录字段路径对应的配置源，未记录的字段使用 source
+func validate(v any, origins map[string]string, source string) error {
+	var violations []Violation
+	validateValue("", reflect.ValueOf(v), func(path, rule, msg string) {
+		violations = append(violations, Violation{Path: path, Rule: rule, Message: msg, Source: originOf(origins, path, source)})
+	})
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func validateValue(path string, v reflect.Value, report func(path, rule, msg string)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			validateValue(path, v.Elem(), report)
+		}
+	case reflect.Struct:
+		for i, t := 0, v.Type(); i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			fp, fv := joinPath(path, f.Name), v.Field(i)
+			if tag := GetTag(f.Tag, _TAG_VALIDATE); tag != "" && tag != "-" {
+				validateField(fp, fv, tag, report)
+			}
+			validateValue(fp, fv, report)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(fmt.Sprintf("%s[%d]", path, i), v.Index(i), report)
+		}
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			validateValue(fmt.Sprintf("%s[%v]", path, it.Key()), it.Value(), report)
+		}
+	}
+}
+
+func validateField(path string, v reflect.Value, tag string, report func(path, rule, msg string)) {
+	rules := ParseRules(tag)
+	if v.IsZero() {
+		for _, rule := range rules {
+			if rule.Name == "required" {
+				report(path, rule.String(), "is required")
+			}
+		}
+		return
+	}
+
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	for _, r := range rules {
+		rule, name, arg := r.String(), r.Name, r.Arg
+		switch name {
+		case "", "required":
+		case "min", "max":
+			n, isLen, err := measure(v)
+			if err != nil {
+				report(path, rule, err.Error())
+				continue
+			}
+
+			limit, err := parseLimit(v, arg, isLen)
+			if err != nil {
+				report(path, rule, fmt.Sprintf("invalid rule %s: %v", rule, err))
+				continue
+			}
+
+			what := "value"
+			if isLen {
+				what = "length"
+			}
+			if name == "min" && n < limit {
+				report(path, rule, fmt.Sprintf("%s must be at least %s", what, arg))
+			} else if name == "max" && n > limit {
+				report(path, rule, fmt.Sprintf("%s must be at most %s", what, arg))
+			}
+		case "oneof":
+			options := strings.Fields(arg)
+			if s := fmt.Sprint(v.Interface()); !contains(options, s) {
+				report(path, rule, fmt.Sprintf("must be one of [%s], got %q", strings.Join(options, " "), s))
+			}
+		default:
+			report(path, rule, fmt.Sprintf("unknown rule %q", name))
+		}
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// measure 返回用于 min/max 比较的数值，isLen 表示比较的是长度
+func measure(v reflect.Value) (n float64, isLen bool, err error) {
+	switch kind := v.Kind(); {
+	case kind == reflect.String, kind == reflect.Slice, kind == reflect.Array, kind == reflect.Map:
+		return float64(v.Len()), true, nil
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return float64(v.Int()), false, nil
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		return float64(v.Uint()), false, nil
+	case kind == reflect.Float32, kind == reflect.Float64:
+		return v.Float(), false, nil
+	default:
+		return 0, false, fmt.Errorf("min/max not supported for %s", v.Type())
+	}
+}
+
+func parseLimit(v reflect.Value, s string, isLen bool) (float64, error) {
+	if !isLen && v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func contains(ss []string, s string) bool {
+	for _, it := range ss {
+		if it == s {
+			return true
+		}
+	}
+	return false
+}
+
+// walkLeaves 遍历 v 中所有非零的叶子值，结构体与 map 继续深入，其余值视为叶子
+func walkLeaves(path string, v reflect.Value, fn func(path string)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkLeaves(path, v.Elem(), fn)
+		}
+	case reflect.Struct:
+		for i, t := 0, v.Type(); i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				walkLeaves(joinPath(path, f.Name), v.Field(i), fn)
+			}
+		}
+	case reflect.Map:
+		for it := v.MapRange(); it.Next(); {
+			walkLeaves(fmt.Sprintf("%s[%v]", path, it.Key()), it.Value(), fn)
+		}
+	default:
+		if v.IsValid() && !v.IsZero() {
+			fn(path)
+		}
+	}
+}
+
+// recordOrigins 记录 layer 中所有非零字段来自 source
+func recordOrigins(origins map[string]string, layer reflect.Value, source string) {
+	if origins != nil {
+		walkLeaves("", layer, func(path string) { origins[path] = source })
+	}
+}
+
+// originOf 查找字段路径或其最近的上级路径对应的配置源
+func originOf(origins map[string]string, path, source string) string {
+	for p := path; p != ""; {
+		if s, ok := origins[p]; ok {
+			return s
+		}
+		if i := strings.LastIndexAny(p, ".["); i >= 0 {
+			p = p[:i]
+		} else {
+			break
+		}
+	}
+	return source
+}
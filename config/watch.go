@@ -0,0 +1,171 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Update 是一次成功重载的结果
+type Update[T any] struct {
+	Value   *T
+	Changes []Change
+}
+
+// Watcher 监听配置文件变更，变更后重新解码到一份新的配置副本
+//
+//	只有解码成功时才会替换当前配置，并通过 OnChange 回调和 Updates 通道发布。
+type Watcher[T any] struct {
+	Decoder  *Codec                       // 使用的解码器，为空时使用全局解码器
+	Interval time.Duration                // 轮询间隔，无法使用 inotify 时生效，默认 2s
+	OnChange func(v *T, changes []Change) // 配置变更回调
+	OnError  func(err error)              // 重载失败回调，失败时保留当前配置
+
+	source  string
+	base    reflect.Value
+	current atomic.Pointer[T]
+
+	mu      sync.Mutex
+	updates chan Update[T]
+}
+
+// NewWatcher 创建配置监听器
+//
+//	base 为每次重载的初始值（如默认配置），同时作为重载前的当前配置。
+func NewWatcher[T any](source string, base *T) *Watcher[T] {
+	if base == nil {
+		base = new(T)
+	}
+	w := &Watcher[T]{source: source, base: clone(reflect.ValueOf(base).Elem())}
+	w.current.Store(base)
+	return w
+}
+
+// Value 返回当前生效的配置，返回值不应被修改
+func (w *Watcher[T]) Value() *T { return w.current.Load() }
+
+// Updates 返回配置变更通道，通道只保留最新的一次变更
+func (w *Watcher[T]) Updates() <-chan Update[T] {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.updates == nil {
+		w.updates = make(chan Update[T], 1)
+	}
+	return w.updates
+}
+
+// Load 立即重新解码配置，成功且有变化时替换当前配置
+func (w *Watcher[T]) Load() (changes []Change, err error) {
+	v := clone(w.base).Addr().Interface().(*T)
+	if err = w.decoder().Decode(w.source, v); err != nil {
+		return
+	}
+
+	old := w.current.Swap(v)
+	if changes = Diff(old, v); len(changes) > 0 {
+		w.publish(Update[T]{Value: v, Changes: changes})
+	}
+	return
+}
+
+// Run 监听配置文件直到 ctx 结束
+func (w *Watcher[T]) Run(ctx context.Context) (err error) {
+	_, path, err := w.decoder().resolve(w.source)
+	if err != nil {
+		return
+	}
+
+	local, ok := w.decoder().localPath(path)
+	if !ok {
+		err = fmt.Errorf("watch non-file source: %s", w.source)
+		return
+	}
+
+	if path, err = filepath.Abs(local); err != nil {
+		return
+	}
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	events, err := watchFile(ctx, path, interval)
+	if err != nil {
+		return
+	}
+
+	for range events {
+		if _, e := w.Load(); e != nil && w.OnError != nil {
+			w.OnError(fmt.Errorf("reload %s: %w", w.source, e))
+		}
+	}
+	return ctx.Err()
+}
+
+func (w *Watcher[T]) decoder() *Codec {
+	if w.Decoder != nil {
+		return w.Decoder
+	}
+	return decoder
+}
+
+func (w *Watcher[T]) publish(u Update[T]) {
+	if w.OnChange != nil {
+		w.OnChange(u.Value, u.Changes)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.updates == nil {
+		return
+	}
+	select {
+	case <-w.updates:
+	default:
+	}
+	w.updates <- u
+}
+
+// pollFile 定时检查文件的修改时间与大小，变化时发出事件
+func pollFile(ctx context.Context, path string, interval time.Duration) <-chan struct{} {
+	events := make(chan struct{})
+	go func() {
+		defer close(events)
+
+		stamp := func() (s string) {
+			if fi, err := os.Stat(path); err == nil {
+				s = fmt.Sprintf("%d:%d", fi.ModTime().UnixNano(), fi.Size())
+			}
+			return
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := stamp()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s := stamp(); s != last {
+					if last = s; s == "" {
+						continue
+					}
+					select {
+					case events <- struct{}{}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return events
+}
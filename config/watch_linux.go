@@ -0,0 +1,109 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// watchFile 使用 inotify 监听文件所在目录，初始化失败时退回轮询
+//
+//	监听目录而不是文件本身，以兼容编辑器先写临时文件再重命名的保存方式。
+func watchFile(ctx context.Context, path string, interval time.Duration) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return pollFile(ctx, path, interval), nil
+	}
+
+	const mask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE | syscall.IN_ATTRIB
+	if _, err = syscall.InotifyAddWatch(fd, filepath.Dir(path), mask); err != nil {
+		syscall.Close(fd)
+		return pollFile(ctx, path, interval), nil
+	}
+
+	f := os.NewFile(uintptr(fd), "inotify")
+	name := filepath.Base(path)
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		defer close(changed)
+
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+
+			for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+				ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				nameBytes := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+				off += syscall.SizeofInotifyEvent + int(ev.Len)
+
+				if cstr(nameBytes) == name {
+					select {
+					case changed <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	events := make(chan struct{})
+	go func() {
+		defer close(events)
+		defer f.Close()
+
+		// 合并短时间内的连续事件，避免读到写了一半的文件
+		const debounce = 100 * time.Millisecond
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-changed:
+				if !ok {
+					return
+				}
+			}
+
+			timer := time.NewTimer(debounce)
+		drain:
+			for {
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case _, ok := <-changed:
+					if !ok {
+						timer.Stop()
+						return
+					}
+					timer.Reset(debounce)
+				case <-timer.C:
+					break drain
+				}
+			}
+
+			select {
+			case events <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func cstr(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
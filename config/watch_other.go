@@ -0,0 +1,12 @@
+//go:build !linux
+
+package config
+
+import (
+	"context"
+	"time"
+)
+
+func watchFile(ctx context.Context, path string, interval time.Duration) (<-chan struct{}, error) {
+	return pollFile(ctx, path, interval), nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.json": `{"name":"nas","port":80}`})
+	path := filepath.Join(dir, "app.json")
+
+	w := NewWatcher(path, &testConfig{Port: 1})
+	w.Decoder = testFactory()
+	w.Interval = 50 * time.Millisecond
+
+	if _, err := w.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if v := w.Value(); v.Name != "nas" || v.Port != 80 {
+		t.Fatalf("unexpected initial value %+v", v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := w.Updates()
+	go w.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte(`{"name":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if v := w.Value(); v.Port != 80 {
+		t.Fatalf("broken config must not be swapped in, got %+v", v)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"name":"nas","port":8080}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case u := <-updates:
+		if u.Value.Port != 8080 || len(u.Changes) != 1 || u.Changes[0].Path != "Port" {
+			t.Fatalf("unexpected update %+v", u)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no update received")
+	}
+}
+
+func TestWatcherOpaqueStruct(t *testing.T) {
+	type config struct {
+		At time.Time `json:"at"`
+	}
+
+	dir := writeFiles(t, map[string]string{"app.json": `{"at":"2024-01-01T00:00:00Z"}`})
+	path := filepath.Join(dir, "app.json")
+
+	w := NewWatcher(path, &config{})
+	w.Decoder = testFactory()
+	if _, err := w.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []Change
+	w.OnChange = func(_ *config, c []Change) { changes = c }
+	if err := os.WriteFile(path, []byte(`{"at":"2025-01-01T00:00:00Z"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 || changes[0].Path != "At" || w.Value().At.Year() != 2025 {
+		t.Fatalf("time change not reported: %v", changes)
+	}
+}
@@ -0,0 +1,93 @@
+package yaml
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hxnas/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalStrict 严格模式解码，一次报告所有未知字段、重复键与类型不匹配的键
+func UnmarshalStrict(v any) config.ReadFunc {
+	return func(data []byte) (err error) {
+		var doc yaml.Node
+		if err = yaml.Unmarshal(data, &doc); err != nil {
+			return
+		}
+
+		var dups []config.KeyError
+		tree, err := readTree(&doc, "", &dups)
+		if err != nil {
+			return
+		}
+
+		if errs := append(dups, config.CheckKeys(tree, v, "yaml")...); len(errs) > 0 {
+			sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+			return &config.StrictError{Errors: errs}
+		}
+		return Unmarshal(v)(data)
+	}
+}
+
+// readTree 将 yaml 节点转换为通用结构，同时记录重复的键
+func readTree(n *yaml.Node, path string, dups *[]config.KeyError) (node any, err error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return readTree(n.Content[0], path, dups)
+		}
+	case yaml.AliasNode:
+		return readTree(n.Alias, path, dups)
+	case yaml.MappingNode:
+		m, seen := map[string]any{}, map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			kn, vn := n.Content[i], n.Content[i+1]
+
+			var child any
+			if kn.Tag == "!!merge" {
+				if child, err = readTree(vn, path, dups); err != nil {
+					return
+				}
+				if merged, ok := child.(map[string]any); ok {
+					for k, v := range merged {
+						if !seen[k] {
+							m[k] = v
+						}
+					}
+				}
+				continue
+			}
+
+			key := kn.Value
+			kp := key
+			if path != "" {
+				kp = path + "." + key
+			}
+
+			if seen[key] {
+				*dups = append(*dups, config.KeyError{Path: kp, Message: "duplicate key"})
+			}
+			seen[key] = true
+
+			if child, err = readTree(vn, kp, dups); err != nil {
+				return
+			}
+			m[key] = child
+		}
+		node = m
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(n.Content))
+		for i, c := range n.Content {
+			var item any
+			if item, err = readTree(c, fmt.Sprintf("%s[%d]", path, i), dups); err != nil {
+				return
+			}
+			items = append(items, item)
+		}
+		node = items
+	case yaml.ScalarNode:
+		err = n.Decode(&node)
+	}
+	return
+}
@@ -1,6 +1,8 @@
 package yaml
 
 import (
+	"bytes"
+
 	"github.com/hxnas/pkg/config"
 	"gopkg.in/yaml.v3"
 )
@@ -9,6 +11,18 @@ func Unmarshal(v any) config.ReadFunc {
 	return func(data []byte) error { return yaml.Unmarshal(data, v) }
 }
 
+func Marshal(v any) (data []byte, err error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err = enc.Encode(v); err == nil {
+		err = enc.Close()
+	}
+	return buf.Bytes(), err
+}
+
 func init() {
 	config.Registry("yaml", Unmarshal, ".yaml", ".yml")
+	config.RegistryEncoder("yaml", Marshal, ".yaml", ".yml")
+	config.RegistryStrict("yaml", UnmarshalStrict, ".yaml", ".yml")
 }
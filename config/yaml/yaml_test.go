@@ -0,0 +1,62 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hxnas/pkg/config"
+)
+
+type testConfig struct {
+	Name   string            `yaml:"name"`
+	Port   int               `yaml:"port"`
+	Mounts []string          `yaml:"mounts"`
+	Labels map[string]string `yaml:"labels"`
+	DB     struct {
+		Host string `yaml:"host"`
+	} `yaml:"db"`
+}
+
+func TestMarshal(t *testing.T) {
+	var v testConfig
+	data := "name: nas\nport: 8080\nmounts: [/data, /backup]\nlabels: {zone: a}\ndb:\n  host: db.local\n"
+	if err := Unmarshal(&v)([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "nas" || v.Port != 8080 || len(v.Mounts) != 2 || v.Labels["zone"] != "a" || v.DB.Host != "db.local" {
+		t.Fatalf("unexpected value %+v", v)
+	}
+
+	out, err := Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var back testConfig
+	if err = UnmarshalStrict(&back)(out); err != nil {
+		t.Fatalf("strict decode of %s: %v", out, err)
+	}
+	if !reflect.DeepEqual(back, v) {
+		t.Fatalf("round trip: got %+v, want %+v", back, v)
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	var v testConfig
+	data := "name: nas\nname: box\nnmae: typo\nport: abc\ndb:\n  hots: x\n"
+	var se *config.StrictError
+	if err := UnmarshalStrict(&v)([]byte(data)); !errors.As(err, &se) {
+		t.Fatalf("expected strict error, got %v", err)
+	}
+
+	want := []string{"db.hots: unknown field", "name: duplicate key", "nmae: unknown field", `port: cannot use "abc" as int`}
+	if len(se.Errors) != len(want) {
+		t.Fatalf("got %v, want %v", se.Errors, want)
+	}
+	for i, ke := range se.Errors {
+		if ke.String() != want[i] {
+			t.Errorf("error %d: got %q, want %q", i, ke, want[i])
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/hxnas/pkg/config"
+)
+
+// Layer 值的来源层，优先级依次升高
+type Layer int
+
+const (
+	LayerDefault Layer = iota // default 标签或结构体初始值
+	LayerConfig               // 配置文件
+	LayerEnv                  // 环境变量
+	LayerFlag                 // 命令行参数
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerConfig:
+		return "config"
+	case LayerEnv:
+		return "env"
+	case LayerFlag:
+		return "flag"
+	default:
+		return "default"
+	}
+}
+
+// Origin 描述字段最终值的来源
+type Origin struct {
+	Layer Layer
+	Key   string // 配置文件路径、环境变量名或参数名，默认值时为空
+}
+
+func (o Origin) String() string {
+	if o.Key == "" {
+		return o.Layer.String()
+	}
+	return o.Layer.String() + " " + o.Key
+}
+
+// Config 注册配置文件参数，Parse 时按 default < 配置文件 < 环境变量 < 命令行参数 的优先级
+// 重新计算所有通过 Struct 绑定的字段。
+//
+//	配置文件被解码到每个通过 Struct 绑定的结构体；未显式指定且默认路径不存在时忽略配置文件。
+//	所有来源叠加完成后统一解析敏感字段引用并执行 config.Validate 校验。
+func (f *FlagSet) Config(name, shorthand, defPath, usage string) {
+	if usage == "" {
+		usage = "配置文件路径"
+	}
+	f.StringP(name, shorthand, defPath, usage)
+	setCompletion(f.Lookup(name), nil, "file")
+	f.configFlag = name
+}
+
+// Origin 返回参数当前值的来源
+func (f *FlagSet) Origin(name string) (o Origin) {
+	if fl := f.Lookup(name); fl != nil {
+		if v, ok := fl.Value.(*Value); ok {
+			o = v.origin
+		}
+	}
+	return
+}
+
+// resolveLayers 按优先级重新计算字段值，未注册配置文件参数时只记录命令行参数的来源
+func (f *FlagSet) resolveLayers() (err error) {
+	if f.configFlag == "" {
+		for _, field := range f.fields {
+			if f.changed(field.Name) {
+				if err = field.Value.reset(field.Value.args); err != nil {
+					return fmt.Errorf("flag --%s: %w", field.Name, err)
+				}
+				field.Value.origin = Origin{Layer: LayerFlag, Key: "--" + field.Name}
+			}
+		}
+		return
+	}
+
+	for _, field := range f.fields {
+		if err = field.Value.reset(field.Value.defs); err != nil {
+			return fmt.Errorf("default of --%s: %w", field.Name, err)
+		}
+		field.Value.origin = Origin{Layer: LayerDefault}
+	}
+
+	if path, _ := f.GetString(f.configFlag); path != "" {
+		before := make([][]string, len(f.fields))
+		for i, field := range f.fields {
+			before[i] = rGet(field.Value.Ref)
+		}
+
+		for _, ptr := range f.structs {
+			if err = config.DecodeWith(path, ptr, config.SkipValidate()); err != nil {
+				if errors.Is(err, fs.ErrNotExist) && !f.changed(f.configFlag) {
+					err = nil
+					break
+				}
+				return fmt.Errorf("config %s: %w", path, err)
+			}
+		}
+
+		for i, field := range f.fields {
+			if values := rGet(field.Value.Ref); !slices.Equal(before[i], values) {
+				if err = field.Value.check(values); err != nil {
+					return fmt.Errorf("config %s (--%s): %w", path, field.Name, err)
+				}
+				field.Value.origin = Origin{Layer: LayerConfig, Key: path}
+			}
+		}
+	}
+
+	for _, field := range f.fields {
+		k, s, _, e := field.lookupEnv()
+		if e != nil {
+			return fmt.Errorf("env %s (--%s): %w", k, field.Name, e)
+		}
+		if s != "" {
+			if err = field.Value.reset(field.Value.split(s)); err != nil {
+				return fmt.Errorf("env %s (--%s): %w", k, field.Name, err)
+			}
+			field.Value.origin = Origin{Layer: LayerEnv, Key: k}
+		}
+	}
+
+	for _, field := range f.fields {
+		if f.changed(field.Name) {
+			if err = field.Value.reset(field.Value.args); err != nil {
+				return fmt.Errorf("flag --%s: %w", field.Name, err)
+			}
+			field.Value.origin = Origin{Layer: LayerFlag, Key: "--" + field.Name}
+		}
+	}
+
+	var errs []error
+	for _, ptr := range f.structs {
+		if e := config.ResolveSecrets(ptr); e != nil {
+			errs = append(errs, e)
+			continue
+		}
+		errs = append(errs, config.Validate(ptr))
+	}
+	return errors.Join(errs...)
+}
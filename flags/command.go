@@ -0,0 +1,217 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+)
+
+// Command 添加子命令，返回子命令的 FlagSet，可继续通过 Struct、Var 等绑定子命令自己的参数或添加下一级子命令。
+//
+//	run 的签名与 sys.Caller 一致，Execute 解析参数后调用最终命中的命令；
+//	子命令继承所有上级命令通过 Persistent 标记的参数，配置文件与打印配置参数被继承时同样生效。
+func (f *FlagSet) Command(name, usage string, run func(ctx context.Context) error) *FlagSet {
+	sub := &FlagSet{
+		pFlagSet: pflag.NewFlagSet(f.commandPath()+" "+name, pflag.ContinueOnError),
+		Run:      run,
+
+		name:   name,
+		usage:  usage,
+		parent: f,
+	}
+	sub.init()
+
+	f.commands = append(f.commands, sub)
+	return sub
+}
+
+// Persistent 将已注册的参数标记为可被子命令继承，不指定参数名时标记当前所有参数
+func (f *FlagSet) Persistent(names ...string) {
+	if f.persistent == nil {
+		f.persistent = map[string]bool{}
+	}
+
+	if len(names) == 0 {
+		f.VisitAll(func(fl *pflag.Flag) {
+			if !f.inherited[fl.Name] {
+				names = append(names, fl.Name)
+			}
+		})
+	}
+
+	for _, name := range names {
+		if f.Lookup(name) == nil {
+			f.errs = append(f.errs, fmt.Errorf("persistent flag --%s is not defined", name))
+			continue
+		}
+		f.persistent[name] = true
+	}
+}
+
+// Execute 解析 args 并执行命中的命令，命令未设置处理函数时显示帮助并返回错误
+func (f *FlagSet) Execute(ctx context.Context, args []string) (err error) {
+	if err = f.Parse(args); err != nil {
+		return
+	}
+
+	cmd := f.Active()
+	if cmd.Run == nil {
+		cmd.Usage()
+		if len(cmd.commands) > 0 && cmd.NArg() > 0 {
+			return fmt.Errorf("unknown command %q for %s", cmd.Arg(0), cmd.commandPath())
+		}
+		return fmt.Errorf("%s: missing command", cmd.commandPath())
+	}
+	return cmd.Run(ctx)
+}
+
+// Active 返回最近一次 Parse 命中的命令，没有子命令时为 f 本身
+func (f *FlagSet) Active() *FlagSet {
+	if f.active != nil {
+		return f.active
+	}
+	return f
+}
+
+// Parent 返回上级命令，根命令返回 nil
+func (f *FlagSet) Parent() *FlagSet { return f.parent }
+
+// parseCommand 解析当前命令的参数后，若剩余参数的第一个为子命令，继续解析子命令
+func (f *FlagSet) parseCommand(args []string) (err error) {
+	if len(f.commands) > 0 {
+		f.SetInterspersed(false)
+	}
+
+	if err = f.pFlagSet.Parse(args); err != nil {
+		return
+	}
+
+	f.active = f
+	if sub := f.lookupCommand(f.Arg(0)); sub != nil {
+		sub.inherit(f)
+		if err = sub.Parse(f.Args()[1:]); err != nil {
+			return
+		}
+		f.active = sub.Active()
+	}
+	return
+}
+
+func (f *FlagSet) lookupCommand(name string) *FlagSet {
+	for _, sub := range f.commands {
+		if name != "" && sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// inherit 添加上级命令可继承的参数，已继承的参数会继续传递给下一级
+func (f *FlagSet) inherit(parent *FlagSet) {
+	if f.inherited == nil {
+		f.inherited = map[string]bool{}
+	}
+
+	for name := range parent.persistent {
+		if f.Lookup(name) != nil {
+			continue
+		}
+
+		f.AddFlag(parent.Lookup(name))
+		f.inherited[name] = true
+		f.Persistent(name)
+
+		switch name {
+		case parent.configFlag:
+			f.configFlag = sels(f.configFlag, name)
+		case parent.printFlag:
+			f.printFlag = sels(f.printFlag, name)
+		}
+	}
+}
+
+// commandPath 返回从根命令开始的完整命令
+func (f *FlagSet) commandPath() string {
+	if f.parent == nil {
+		return f.name
+	}
+	return f.parent.commandPath() + " " + f.name
+}
+
+// chain 返回从 f 到当前命中命令的路径
+func (f *FlagSet) chain() (cmds []*FlagSet) {
+	for cmd := f.Active(); cmd != nil; cmd = cmd.parent {
+		cmds = append([]*FlagSet{cmd}, cmds...)
+		if cmd == f {
+			break
+		}
+	}
+	return
+}
+
+func (f *FlagSet) root() *FlagSet {
+	if f.parent == nil {
+		return f
+	}
+	return f.parent.root()
+}
+
+// changed 判断参数是否在命令行中设置，包括在子命令中设置的继承参数
+func (f *FlagSet) changed(name string) bool {
+	fl := f.Lookup(name)
+	return fl != nil && fl.Changed
+}
+
+func (f *FlagSet) printUsage(out io.Writer) {
+	fmt.Fprintf(out, "%s", f.commandPath())
+	if ver := f.root().Version; ver != "" {
+		fmt.Fprintf(out, " -- version %s", ver)
+	}
+	fmt.Fprintf(out, "\n\n")
+
+	if f.usage != "" {
+		fmt.Fprintf(out, "%s\n\n", f.usage)
+	}
+
+	fmt.Fprintf(out, "USAGE:\n")
+	if len(f.commands) > 0 {
+		fmt.Fprintf(out, "  %s [...Flags] <command>\n\n", f.commandPath())
+
+		width := 0
+		for _, sub := range f.commands {
+			width = max(width, len(sub.name))
+		}
+
+		fmt.Fprintf(out, "Commands:\n")
+		for _, sub := range f.commands {
+			fmt.Fprintf(out, "  %-*s   %s\n", width, sub.name, sub.usage)
+		}
+		fmt.Fprintln(out)
+	} else {
+		fmt.Fprintf(out, "  %s [...Flags]\n\n", f.commandPath())
+	}
+
+	local, global := pflag.NewFlagSet("local", pflag.ContinueOnError), pflag.NewFlagSet("global", pflag.ContinueOnError)
+	local.SortFlags, global.SortFlags = false, false
+	f.VisitAll(func(fl *pflag.Flag) {
+		if f.inherited[fl.Name] {
+			global.AddFlag(fl)
+		} else {
+			local.AddFlag(fl)
+		}
+	})
+
+	fmt.Fprintf(out, "Flags:\n")
+	fmt.Fprintln(out, local.FlagUsagesWrapped(0))
+
+	if global.HasFlags() {
+		fmt.Fprintf(out, "Global Flags:\n")
+		fmt.Fprintln(out, global.FlagUsagesWrapped(0))
+	}
+
+	if len(f.commands) > 0 {
+		fmt.Fprintf(out, "Use \"%s <command> --help\" for more information about a command.\n", f.commandPath())
+	}
+}
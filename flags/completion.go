@@ -0,0 +1,324 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	completeCmd = "__complete" // 补全脚本调用的隐藏命令
+
+	annoEnum     = "flags_enum"     // 参数的可选值
+	annoComplete = "flags_complete" // 参数的补全提示
+
+	completeNone = ":"     // 只使用给出的候选项
+	completeFile = ":file" // 补全文件，可附带扩展名，如 :file yaml yml
+	completeDir  = ":dir"  // 补全目录
+)
+
+// CompleteFunc 根据正在输入的内容返回参数值的候选项，候选项可用 \t 附加说明
+type CompleteFunc func(prefix string) []string
+
+// Complete 为参数注册动态补全函数，补全时通过隐藏的 __complete 调用执行
+func (f *FlagSet) Complete(name string, fn CompleteFunc) {
+	if f.Lookup(name) == nil {
+		f.errs = append(f.errs, fmt.Errorf("complete flag --%s is not defined", name))
+		return
+	}
+
+	if f.completers == nil {
+		f.completers = map[string]CompleteFunc{}
+	}
+	f.completers[name] = fn
+}
+
+// Completion 生成 shell 补全脚本，shell 可以是 bash、zsh 或 fish
+//
+//	脚本在补全时执行 `<命令> __complete <已输入的参数...>`，由 Parse 输出候选项：
+//	子命令、参数名、枚举值（enum 或 choices 标签，以及 validate 标签的 oneof 规则）、动态补全函数的结果，
+//	以及 complete:"file"、complete:"dir"、complete:"file:yaml,yml" 标记的路径补全；
+//	没有 complete 标签时按字段推断：名称以 File、Path 结尾的字符串字段补全文件，以 Dir 结尾的补全目录。
+func (f *FlagSet) Completion(w io.Writer, shell string) error {
+	tpl, ok := completionTemplates[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell: %s", shell)
+	}
+
+	prog := f.root().name
+	return tpl.Execute(w, map[string]string{
+		"Prog": prog,
+		"Func": "__" + nonWordRe.ReplaceAllString(prog, "_") + "_complete",
+	})
+}
+
+// Completions 返回已输入参数 args（最后一个为正在输入的内容）的候选项，最后一行为补全指令
+func (f *FlagSet) Completions(args []string) (lines []string) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	cmd, words, cur := f, args[:len(args)-1], args[len(args)-1]
+	var pending *pflag.Flag // 等待值的参数
+	for _, word := range words {
+		switch {
+		case pending != nil:
+			pending = nil
+		case word == "--":
+			return append(lines, completeFile)
+		case strings.HasPrefix(word, "-") && !strings.Contains(word, "="):
+			if fl := cmd.lookupArg(word); fl != nil && fl.NoOptDefVal == "" {
+				pending = fl
+			}
+		case !strings.HasPrefix(word, "-"):
+			if sub := cmd.lookupCommand(word); sub != nil {
+				sub.inherit(cmd)
+				cmd = sub
+			}
+		}
+	}
+
+	if pending != nil {
+		return cmd.completeValue(pending, "", cur)
+	}
+
+	if name, value, ok := strings.Cut(cur, "="); ok && strings.HasPrefix(name, "-") {
+		if fl := cmd.lookupArg(name); fl != nil {
+			return cmd.completeValue(fl, name+"=", value)
+		}
+		return append(lines, completeNone)
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		cmd.VisitAll(func(fl *pflag.Flag) {
+			if fl.Hidden || fl.Deprecated != "" {
+				return
+			}
+			if name := "--" + fl.Name; strings.HasPrefix(name, cur) {
+				lines = append(lines, name+"\t"+firstLine(fl.Usage))
+			}
+		})
+		return append(lines, completeNone)
+	}
+
+	if len(cmd.commands) == 0 {
+		return append(lines, completeFile)
+	}
+
+	for _, sub := range cmd.commands {
+		if strings.HasPrefix(sub.name, cur) {
+			lines = append(lines, sub.name+"\t"+firstLine(sub.usage))
+		}
+	}
+	return append(lines, completeNone)
+}
+
+// completeValue 补全参数的值，prefix 为候选项需要保留的 --name= 前缀
+func (f *FlagSet) completeValue(fl *pflag.Flag, prefix, value string) (lines []string) {
+	if fn := f.completer(fl.Name); fn != nil {
+		for _, s := range fn(value) {
+			lines = append(lines, prefix+s)
+		}
+		return append(lines, completeNone)
+	}
+
+	values := fl.Annotations[annoEnum]
+	if len(values) == 0 && fl.Value.Type() == "bool" {
+		values = []string{"true", "false"}
+	}
+
+	if len(values) > 0 {
+		for _, s := range values {
+			if strings.HasPrefix(s, value) {
+				lines = append(lines, prefix+s)
+			}
+		}
+		return append(lines, completeNone)
+	}
+
+	switch hint := fl.Annotations[annoComplete]; {
+	case len(hint) == 0:
+		return append(lines, completeNone)
+	case hint[0] == "dir":
+		return append(lines, completeDir)
+	default:
+		return append(lines, strings.Join(append([]string{completeFile}, hint[1:]...), " "))
+	}
+}
+
+// completer 查找参数的动态补全函数，继承的参数使用上级命令注册的函数
+func (f *FlagSet) completer(name string) CompleteFunc {
+	for cmd := f; cmd != nil; cmd = cmd.parent {
+		if fn, ok := cmd.completers[name]; ok {
+			return fn
+		}
+	}
+	return nil
+}
+
+// lookupArg 按命令行中的写法（--name 或 -n）查找参数
+func (f *FlagSet) lookupArg(arg string) *pflag.Flag {
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		return f.Lookup(name)
+	}
+	if s := strings.TrimPrefix(arg, "-"); s != "" {
+		return f.ShorthandLookup(s[len(s)-1:])
+	}
+	return nil
+}
+
+// printCompletions 处理补全脚本的 __complete 调用，未设置 HandleCompletion 时输出到标准输出并退出
+func (f *FlagSet) printCompletions(args []string) {
+	lines := f.Completions(args)
+	if f.HandleCompletion != nil {
+		f.HandleCompletion(lines)
+		return
+	}
+
+	for _, line := range lines {
+		fmt.Fprintln(os.Stdout, line)
+	}
+	os.Exit(0)
+}
+
+// setCompletion 将可选值与补全提示记录到参数的注解中
+func setCompletion(fl *pflag.Flag, enum []string, hint string) {
+	if fl.Annotations == nil {
+		fl.Annotations = map[string][]string{}
+	}
+
+	if len(enum) > 0 {
+		fl.Annotations[annoEnum] = enum
+	}
+
+	if hint != "" {
+		kind, exts, _ := strings.Cut(hint, ":")
+		fl.Annotations[annoComplete] = append([]string{kind}, fieldSpilt(exts)...)
+	}
+}
+
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	return s
+}
+
+var nonWordRe = regexp.MustCompile(`\W`)
+
+var completionTemplates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(`# bash completion for {{.Prog}}
+{{.Func}}() {
+    local line="${COMP_LINE:0:COMP_POINT}" cur val IFS=$'\n'
+    local -a words lines
+    IFS=$' \t\n' read -r -a words <<< "$line"
+    [[ "$line" =~ [[:space:]]$ ]] && words+=("")
+    cur="${words[${#words[@]}-1]}"
+
+    mapfile -t lines < <("${words[0]}" __complete "${words[@]:1}" 2>/dev/null)
+    [[ ${#lines[@]} -eq 0 ]] && return
+    local directive="${lines[${#lines[@]}-1]}"
+    unset 'lines[${#lines[@]}-1]'
+
+    val="$cur"
+    [[ "$cur" == -*=* ]] && val="${cur#*=}"
+
+    COMPREPLY=()
+    case "$directive" in
+    :dir)
+        compopt -o filenames 2>/dev/null
+        COMPREPLY=($(compgen -d -- "$val"))
+        ;;
+    :file*)
+        compopt -o filenames 2>/dev/null
+        local -a exts
+        local c e
+        IFS=' ' read -r -a exts <<< "${directive#:file}"
+        for c in $(compgen -f -- "$val"); do
+            if [[ ${#exts[@]} -eq 0 || -d "$c" ]]; then
+                COMPREPLY+=("$c")
+                continue
+            fi
+            for e in "${exts[@]}"; do
+                [[ "$c" == *."$e" ]] && COMPREPLY+=("$c") && break
+            done
+        done
+        ;;
+    *)
+        local c
+        for c in "${lines[@]}"; do
+            c="${c%%$'\t'*}"
+            [[ "$cur" == -*=* && "$COMP_WORDBREAKS" == *=* ]] && c="${c#*=}"
+            COMPREPLY+=("$c")
+        done
+        ;;
+    esac
+}
+complete -F {{.Func}} {{.Prog}}
+`)),
+	"zsh": template.Must(template.New("zsh").Parse(`#compdef {{.Prog}}
+# zsh completion for {{.Prog}}
+{{.Func}}() {
+    local -a lines cands
+    local directive l v d assign
+    lines=("${(@f)$(${words[1]} __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    (( ${#lines} )) || return 1
+    directive=${lines[-1]}
+    lines=("${(@)lines[1,-2]}")
+
+    [[ ${words[CURRENT]} == -*=* ]] && assign=1 && compset -P '*='
+
+    case $directive in
+    :dir) _files -/ ;;
+    :file) _files ;;
+    :file\ *) _files -g "*.(${(j:|:)${(s: :)directive#:file }})" ;;
+    *)
+        for l in "${lines[@]}"; do
+            v=${l%%$'\t'*}
+            (( assign )) && v=${v#*=}
+            [[ $l == *$'\t'* ]] && d=${l#*$'\t'} || d=
+            cands+=("${v//:/\\:}${d:+:$d}")
+        done
+        _describe -t values values cands
+        ;;
+    esac
+}
+
+if [[ "${funcstack[1]}" == "{{.Func}}" ]]; then
+    {{.Func}} "$@"
+else
+    compdef {{.Func}} {{.Prog}}
+fi
+`)),
+	"fish": template.Must(template.New("fish").Parse(`# fish completion for {{.Prog}}
+function {{.Func}}
+    set -l tokens (commandline -opc)
+    set -l cur (commandline -ct)
+    set -l lines ($tokens[1] __complete $tokens[2..-1] $cur 2>/dev/null)
+    test (count $lines) -gt 0; or return
+    set -l directive $lines[-1]
+    set -e lines[-1]
+
+    set -l pre (string match -r -- '^-[^=]*=' $cur)
+    set -l val (string replace -r -- '^-[^=]*=' '' $cur)
+
+    switch $directive
+        case :dir
+            __fish_complete_directories $val | string replace -r -- '^' "$pre"
+        case ':file*'
+            set -l exts (string split -n ' ' -- (string replace -- ':file' '' $directive))
+            set -l paths (__fish_complete_path $val)
+            if test (count $exts) -gt 0
+                set paths (string match -r -- '^[^\t]*(/|\.('(string join '|' $exts)'))(\t.*)?$' $paths)
+            end
+            string replace -r -- '^' "$pre" $paths
+        case '*'
+            printf '%s\n' $lines
+    end
+end
+complete -c {{.Prog}} -f -a '({{.Func}})'
+`)),
+}
@@ -0,0 +1,118 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// checkRules 检查从当前命令到 Parse 命中的子命令绑定的所有字段的约束，返回所有未满足的规则：
+//
+//	required:"true"    必须设置（命令行、环境变量、配置文件或非零的默认值）
+//	exclusive:"group"  同组参数最多显式设置一个
+//	anyof:"group"      同组参数至少设置一个
+//	requires:"a,b"     显式设置该参数时必须同时设置参数 a、b，嵌套结构体中的参数名自动添加前缀
+func (f *FlagSet) checkRules() error {
+	var fields []*FlagField
+	for _, cmd := range f.chain() {
+		fields = append(fields, cmd.fields...)
+	}
+
+	var (
+		errs      []error
+		groups    []string
+		exclusive = map[string][]*FlagField{}
+		anyOf     = map[string][]*FlagField{}
+	)
+
+	for _, field := range fields {
+		if field.Required && !field.present() {
+			errs = append(errs, fmt.Errorf("%s is required", field.hint()))
+		}
+
+		for _, g := range field.Exclusive {
+			if exclusive[g] == nil && anyOf[g] == nil {
+				groups = append(groups, g)
+			}
+			exclusive[g] = append(exclusive[g], field)
+		}
+
+		for _, g := range field.AnyOf {
+			if exclusive[g] == nil && anyOf[g] == nil {
+				groups = append(groups, g)
+			}
+			anyOf[g] = append(anyOf[g], field)
+		}
+
+		if !field.explicit() {
+			continue
+		}
+
+		for _, name := range field.Requires {
+			switch target := fieldNamed(fields, name); {
+			case target == nil:
+				errs = append(errs, fmt.Errorf("--%s requires undefined flag --%s", field.Name, name))
+			case !target.present():
+				errs = append(errs, fmt.Errorf("--%s requires %s", field.Name, target.hint()))
+			}
+		}
+	}
+
+	for _, g := range groups {
+		var set, hints []string
+		for _, field := range exclusive[g] {
+			if field.explicit() {
+				set = append(set, fmt.Sprintf("--%s from %s", field.Name, field.Value.origin))
+			}
+		}
+		if len(set) > 1 {
+			errs = append(errs, fmt.Errorf("%s are mutually exclusive, got %s", flagNames(exclusive[g]), strings.Join(set, " and ")))
+		}
+
+		if members := anyOf[g]; len(members) > 0 {
+			for _, field := range members {
+				if field.present() {
+					hints = nil
+					break
+				}
+				hints = append(hints, field.hint())
+			}
+			if len(hints) > 0 {
+				errs = append(errs, fmt.Errorf("at least one of %s is required", strings.Join(hints, ", ")))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// explicit 判断字段是否由配置文件、环境变量或命令行参数设置
+func (f *FlagField) explicit() bool { return f.Value.origin.Layer != LayerDefault }
+
+// present 判断字段是否已设置，非零的默认值同样视为已设置
+func (f *FlagField) present() bool { return f.explicit() || len(rGet(f.Value.Ref)) > 0 }
+
+// hint 返回参数名及可以设置该参数的环境变量
+func (f *FlagField) hint() string {
+	if len(f.Env) == 0 {
+		return "--" + f.Name
+	}
+	return fmt.Sprintf("--%s (env: %s)", f.Name, strings.Join(f.envs(), ", "))
+}
+
+func fieldNamed(fields []*FlagField, name string) *FlagField {
+	for _, field := range fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func flagNames(fields []*FlagField) string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = "--" + field.Name
+	}
+	return strings.Join(names, ", ")
+}
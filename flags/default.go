@@ -1,6 +1,7 @@
 package flags
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,6 +19,27 @@ func Var(obj any, name, shorthand, usage string) {
 	Default.Var(obj, name, shorthand, usage)
 }
 
+func Config(name, shorthand, defPath, usage string) {
+	Default.Config(name, shorthand, defPath, usage)
+}
+
+func PrintConfig(name, usage string) {
+	Default.PrintConfig(name, usage)
+}
+
+func Command(name, usage string, run func(ctx context.Context) error) *FlagSet {
+	return Default.Command(name, usage, run)
+}
+
+func Persistent(names ...string) {
+	Default.Persistent(names...)
+}
+
+// Execute 解析命令行参数并执行命中的命令
+func Execute(ctx context.Context) error {
+	return Default.Execute(ctx, os.Args[1:])
+}
+
 func Parse() {
 	if err := Default.Parse(os.Args[1:]); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
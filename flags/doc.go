@@ -0,0 +1,297 @@
+package flags
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// SetDescription 设置命令的说明，显示在帮助信息与生成的文档中
+func (f *FlagSet) SetDescription(desc string) { f.usage = sels(desc, f.usage) }
+
+// Commands 返回子命令
+func (f *FlagSet) Commands() []*FlagSet { return f.commands }
+
+// flagDoc 生成文档所需的参数信息
+type flagDoc struct {
+	Name       string
+	Shorthand  string
+	Type       string
+	Usage      string
+	Default    string
+	Deprecated string
+	Env        []string
+	Enum       []string
+	Required   bool
+	Inherited  bool
+}
+
+// flagDocs 收集命令的参数信息，包括从上级命令继承的参数
+func (f *FlagSet) flagDocs() (docs []flagDoc) {
+	f.inheritChain()
+
+	f.VisitAll(func(fl *pflag.Flag) {
+		if fl.Hidden {
+			return
+		}
+
+		d := flagDoc{
+			Name:       fl.Name,
+			Shorthand:  fl.Shorthand,
+			Type:       fl.Value.Type(),
+			Usage:      fl.Usage,
+			Deprecated: fl.Deprecated,
+			Enum:       fl.Annotations[annoEnum],
+			Inherited:  f.inherited[fl.Name],
+		}
+
+		if d.Type == "bool" {
+			d.Type = ""
+		}
+
+		// 通过 Struct 绑定的参数取 default 标签或结构体初始值，DefValue 可能已被环境变量覆盖
+		def := fl.DefValue
+		if field := f.fieldByName(fl.Name); field != nil {
+			d.Usage, d.Env, d.Required = sels(field.Usage, field.Field.Name), field.Env, field.Required
+			def = field.Value.format(field.defaults())
+		}
+
+		switch def {
+		case "", "false", "0", "[]":
+		default:
+			d.Default = def
+		}
+
+		docs = append(docs, d)
+	})
+	return
+}
+
+// inheritChain 从根命令开始依次继承上级命令的参数
+func (f *FlagSet) inheritChain() {
+	if f.parent != nil {
+		f.parent.inheritChain()
+		f.inherit(f.parent)
+	}
+}
+
+// fieldByName 在命令及其上级命令中查找通过 Struct 绑定的参数
+func (f *FlagSet) fieldByName(name string) *FlagField {
+	for cmd := f; cmd != nil; cmd = cmd.parent {
+		for _, field := range cmd.fields {
+			if field.Name == name {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+// synopsis 返回命令的用法
+func (f *FlagSet) synopsis() string {
+	if len(f.commands) > 0 {
+		return f.commandPath() + " [flags] <command>"
+	}
+	return f.commandPath() + " [flags]"
+}
+
+// ManPage 生成命令的 roff 格式 man 手册，子命令的手册名称为以 - 连接的完整命令，如 tool-serve(1)
+func (f *FlagSet) ManPage(w io.Writer) error {
+	name := strings.ReplaceAll(f.commandPath(), " ", "-")
+	ver := f.root().Version
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH \"%s\" \"1\" \"\" \"%s\" \"%s\"\n", roff(strings.ToUpper(name)), roff(strings.TrimSpace(f.root().name+" "+ver)), roff(f.root().name+" Manual"))
+
+	b.WriteString(".SH NAME\n")
+	if desc := firstLine(f.usage); desc != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", roff(name), roff(desc))
+	} else {
+		fmt.Fprintf(&b, "%s\n", roff(name))
+	}
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&b, "\\fB%s\\fR [\\fIflags\\fR]", roff(f.commandPath()))
+	if len(f.commands) > 0 {
+		b.WriteString(" \\fIcommand\\fR")
+	}
+	b.WriteString("\n")
+
+	if f.usage != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		for _, line := range strings.Split(f.usage, "\n") {
+			b.WriteString(roffLine(line) + "\n")
+		}
+	}
+
+	docs := f.flagDocs()
+	for _, section := range []struct {
+		title     string
+		inherited bool
+	}{{"OPTIONS", false}, {"GLOBAL OPTIONS", true}} {
+		var items []flagDoc
+		for _, d := range docs {
+			if d.Inherited == section.inherited {
+				items = append(items, d)
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, ".SH \"%s\"\n", section.title)
+		for _, d := range items {
+			b.WriteString(".TP\n")
+			if d.Shorthand != "" {
+				fmt.Fprintf(&b, "\\fB\\-%s\\fR, ", roff(d.Shorthand))
+			}
+			fmt.Fprintf(&b, "\\fB\\-\\-%s\\fR", roff(d.Name))
+			if d.Type != "" {
+				fmt.Fprintf(&b, " \\fI%s\\fR", roff(d.Type))
+			}
+			b.WriteString("\n" + roffLine(d.Usage) + "\n")
+
+			for _, it := range d.notes() {
+				b.WriteString(".br\n" + roffLine(it) + "\n")
+			}
+		}
+	}
+
+	if len(f.commands) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, sub := range f.commands {
+			fmt.Fprintf(&b, ".TP\n\\fB%s\\fR\n%s\n", roff(sub.name), roffLine(firstLine(sub.usage)))
+			fmt.Fprintf(&b, "See \\fB%s\\fR(1).\n", roff(name+"-"+sub.name))
+		}
+	}
+
+	var envs []string
+	for _, d := range docs {
+		for _, env := range d.Env {
+			envs = append(envs, fmt.Sprintf(".TP\n\\fB%s\\fR\nSame as \\fB\\-\\-%s\\fR.\n", roff(env), roff(d.Name)))
+		}
+	}
+	if len(envs) > 0 {
+		b.WriteString(".SH ENVIRONMENT\n" + strings.Join(envs, ""))
+	}
+
+	if ver != "" {
+		fmt.Fprintf(&b, ".SH VERSION\n%s\n", roffLine(ver))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Markdown 生成命令及其所有子命令的 Markdown 参考文档
+func (f *FlagSet) Markdown(w io.Writer) error {
+	var b strings.Builder
+	f.markdown(&b, 1)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *FlagSet) markdown(b *strings.Builder, level int) {
+	heading := strings.Repeat("#", min(level, 6))
+	sub := strings.Repeat("#", min(level+1, 6))
+
+	fmt.Fprintf(b, "%s %s\n\n", heading, f.commandPath())
+	if f.usage != "" {
+		fmt.Fprintf(b, "%s\n\n", f.usage)
+	}
+	if ver := f.root().Version; ver != "" && f.parent == nil {
+		fmt.Fprintf(b, "Version: `%s`\n\n", ver)
+	}
+
+	fmt.Fprintf(b, "```\n%s\n```\n\n", f.synopsis())
+
+	docs := f.flagDocs()
+	for _, section := range []struct {
+		title     string
+		inherited bool
+	}{{"Flags", false}, {"Global Flags", true}} {
+		var rows []string
+		for _, d := range docs {
+			if d.Inherited != section.inherited {
+				continue
+			}
+
+			flag := "--" + d.Name
+			if d.Shorthand != "" {
+				flag = "-" + d.Shorthand + ", " + flag
+			}
+			if d.Type != "" {
+				flag += " " + d.Type
+			}
+
+			desc := d.Usage
+			if notes := d.notes(); len(notes) > 0 {
+				desc += "<br>" + strings.Join(notes, "<br>")
+			}
+
+			rows = append(rows, fmt.Sprintf("| `%s` | %s | %s |", flag, mdCode(d.Env), mdCell(desc)))
+		}
+
+		if len(rows) > 0 {
+			fmt.Fprintf(b, "%s %s\n\n| Flag | Env | Description |\n| --- | --- | --- |\n%s\n\n", sub, section.title, strings.Join(rows, "\n"))
+		}
+	}
+
+	if len(f.commands) > 0 {
+		fmt.Fprintf(b, "%s Commands\n\n", sub)
+		for _, c := range f.commands {
+			fmt.Fprintf(b, "- [%s](#%s) - %s\n", c.name, mdAnchor(c.commandPath()), mdCell(firstLine(c.usage)))
+		}
+		b.WriteString("\n")
+
+		for _, c := range f.commands {
+			c.markdown(b, level+1)
+		}
+	}
+}
+
+// notes 返回参数是否必填、默认值、可选值与弃用信息
+func (d flagDoc) notes() (notes []string) {
+	if d.Required {
+		notes = append(notes, "Required")
+	}
+	if d.Default != "" {
+		notes = append(notes, "Default: "+d.Default)
+	}
+	if len(d.Enum) > 0 {
+		notes = append(notes, "Values: "+strings.Join(d.Enum, ", "))
+	}
+	if d.Deprecated != "" {
+		notes = append(notes, "Deprecated: "+d.Deprecated)
+	}
+	return
+}
+
+var roffReplacer = strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+
+func roff(s string) string { return roffReplacer.Replace(s) }
+
+// roffLine 转义一行正文，避免以 . 或 ' 开头的内容被当作 roff 指令
+func roffLine(s string) string {
+	s = roff(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+var mdReplacer = strings.NewReplacer("|", `\|`, "\n", "<br>")
+
+func mdCell(s string) string { return mdReplacer.Replace(s) }
+
+func mdCode(items []string) string {
+	codes := make([]string, len(items))
+	for i, it := range items {
+		codes[i] = "`" + it + "`"
+	}
+	return strings.Join(codes, ", ")
+}
+
+func mdAnchor(s string) string { return strings.ToLower(strings.ReplaceAll(s, " ", "-")) }
@@ -0,0 +1,217 @@
+package flags
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/hxnas/pkg/config"
+)
+
+// PrintConfig 注册打印配置参数，如 --print-config[=yaml|json|ini]，不指定格式时使用 yaml。
+//
+//	Parse 完成所有来源的叠加后，将通过 Struct 绑定的结构体按指定格式编码输出：
+//	敏感字段被替换为 ******，每个参数对应的值以注释标注其来源（default、config、env 或 flag）。
+//	对应格式的编码函数需已注册到 config（如导入 config/yaml），json 输出带 // 注释，即 jsonc。
+func (f *FlagSet) PrintConfig(name, usage string) {
+	if usage == "" {
+		usage = "打印最终生效的配置后退出 (yaml|json|ini)"
+	}
+	f.String(name, "", usage)
+	f.Lookup(name).NoOptDefVal = "yaml"
+	f.printFlag = name
+}
+
+// DumpConfig 按 format 编码通过 Struct 绑定的结构体，敏感字段被隐藏，参数值以注释标注来源
+//
+//	包含子命令时依次输出从当前命令到 Parse 命中的子命令绑定的结构体。
+func (f *FlagSet) DumpConfig(format string) ([]byte, error) {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+
+	var structs []any
+	for _, cmd := range f.chain() {
+		structs = append(structs, cmd.structs...)
+	}
+
+	var buf bytes.Buffer
+	for i, ptr := range structs {
+		data, err := config.Marshal(format, config.Redact(ptr))
+		if err != nil {
+			return nil, err
+		}
+
+		origins := map[string]Origin{}
+		f.fieldOrigins("", reflect.ValueOf(ptr), format, origins)
+
+		if i > 0 {
+			if format == "yaml" || format == "yml" {
+				buf.WriteString("---")
+			}
+			buf.WriteString("\n")
+		}
+		buf.Write(annotate(data, format, origins))
+	}
+	return buf.Bytes(), nil
+}
+
+// printConfig 处理打印配置参数，未设置 HandlePrintConfig 时输出到标准输出并退出
+func (f *FlagSet) printConfig() (err error) {
+	if f.printFlag == "" || !f.changed(f.printFlag) {
+		return
+	}
+
+	format, _ := f.GetString(f.printFlag)
+
+	var data []byte
+	if data, err = f.DumpConfig(format); err != nil {
+		return
+	}
+
+	if f.HandlePrintConfig != nil {
+		f.HandlePrintConfig(data)
+		return
+	}
+
+	os.Stdout.Write(data)
+	os.Exit(0)
+	return
+}
+
+// fieldOrigins 按格式的键名遍历结构体，记录每个绑定参数的字段路径（小写）及其来源
+func (f *FlagSet) fieldOrigins(path string, v reflect.Value, format string, origins map[string]Origin) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i, t := 0, v.Type(); i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(getTag(sf.Tag, format), ",")
+		if name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if name == "" && sf.Anonymous && format != "yaml" || strings.Contains(opts, "inline") {
+			f.fieldOrigins(path, fv, format, origins)
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		key := strings.ToLower(name)
+		if path != "" {
+			key = path + "." + key
+		}
+
+		if field := f.fieldOf(fv); field != nil {
+			origins[key] = field.Value.origin
+			continue
+		}
+		f.fieldOrigins(key, fv, format, origins)
+	}
+}
+
+func (f *FlagSet) fieldOf(v reflect.Value) *FlagField {
+	if !v.CanAddr() {
+		return nil
+	}
+
+	for _, cmd := range f.chain() {
+		for _, field := range cmd.fields {
+			if field.Value.Ref.CanAddr() && field.Value.Ref.UnsafeAddr() == v.UnsafeAddr() && field.Value.typ == v.Type() {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	yamlKeyRe    = regexp.MustCompile(`^(\s*)(- )?("(?:[^"\\]|\\.)*"|'[^']*'|[^\s#'"-][^:#]*):(\s|$)`)
+	jsonKeyRe    = regexp.MustCompile(`^(\s*)"((?:[^"\\]|\\.)*)"\s*:`)
+	iniSectionRe = regexp.MustCompile(`^\s*\[+([^\]]+)\]+\s*$`)
+	iniKeyRe     = regexp.MustCompile(`^(\s*)("[^"]*"|[^\s=;#\[][^=]*?)\s*=`)
+)
+
+// annotate 在编码结果中每个参数值所在行的行尾添加来源注释
+func annotate(data []byte, format string, origins map[string]Origin) []byte {
+	comment := "#"
+	switch format {
+	case "json", "jsonc":
+		comment = "//"
+	case "ini":
+		comment = ";"
+	}
+
+	type entry struct {
+		indent int
+		key    string
+	}
+	var stack []entry
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		var indent int
+		var key string
+
+		switch format {
+		case "yaml", "yml":
+			m := yamlKeyRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			indent, key = len(m[1])+len(m[2]), strings.Trim(m[3], `"'`)
+		case "json", "jsonc":
+			m := jsonKeyRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			indent, key = len(m[1]), m[2]
+		default:
+			if m := iniSectionRe.FindStringSubmatch(line); m != nil {
+				stack = stack[:0]
+				for _, s := range strings.Split(m[1], ".") {
+					stack = append(stack, entry{-1, strings.Trim(strings.TrimSpace(s), `"`)})
+				}
+				continue
+			}
+
+			m := iniKeyRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			indent, key = 0, strings.Trim(m[2], `"`)
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, entry{indent, key})
+
+		keys := make([]string, len(stack))
+		for j, e := range stack {
+			keys[j] = strings.ToLower(e.key)
+		}
+
+		if o, ok := origins[strings.Join(keys, ".")]; ok {
+			lines[i] = fmt.Sprintf("%s %s %s", line, comment, o)
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
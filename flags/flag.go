@@ -1,6 +1,7 @@
 package flags
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -19,9 +20,25 @@ type FlagSet struct {
 	*pFlagSet
 	Version           string
 	Prefix            Prefix
-	HandleVersionFlag func(version string)
-
-	errs []error
+	HandleVersionFlag func(version string) //处理 --version，设置后 Parse 在调用它之后直接返回
+	HandlePrintConfig func(dump []byte)
+	HandleCompletion  func(lines []string)            //处理 __complete 调用的候选项，未设置时输出到标准输出并退出
+	Run               func(ctx context.Context) error //命令的处理函数，签名与 sys.Caller 一致
+
+	errs       []error
+	fields     []*FlagField //通过 Struct 绑定的字段
+	structs    []any        //通过 Struct 绑定的结构体指针
+	configFlag string       //配置文件参数名
+	printFlag  string       //打印配置参数名
+
+	name       string                  //命令名称
+	usage      string                  //子命令说明
+	parent     *FlagSet                //上级命令
+	commands   []*FlagSet              //子命令
+	active     *FlagSet                //Parse 命中的命令
+	persistent map[string]bool         //可被子命令继承的参数
+	inherited  map[string]bool         //从上级命令继承的参数
+	completers map[string]CompleteFunc //参数的动态补全函数
 }
 
 type Prefix struct {
@@ -32,28 +49,23 @@ type Prefix struct {
 type pFlagSet = pflag.FlagSet
 
 func (f *FlagSet) Init(name string) {
-	out := os.Stderr
-
 	if f.pFlagSet == nil {
 		f.pFlagSet = pflag.NewFlagSet(name, pflag.ContinueOnError)
 	}
 
 	pflag.ErrHelp = fmt.Errorf("use %s [...Flags] to execute", name)
 
+	f.name = name
+	f.init()
+}
+
+func (f *FlagSet) init() {
+	out := os.Stderr
+
 	f.SetOutput(out)
 	f.SortFlags = false
 
-	f.Usage = func() {
-		fmt.Fprintf(out, "%s", name)
-		if f.Version != "" {
-			fmt.Fprintf(out, " -- version %s", f.Version)
-		}
-		fmt.Fprintf(out, "\n\n")
-		fmt.Fprintf(out, "USAGE:\n")
-		fmt.Fprintf(out, "  %s [...Flags]\n\n", name)
-		fmt.Fprintf(out, "Flags:\n")
-		fmt.Fprintln(out, f.FlagUsagesWrapped(0))
-	}
+	f.Usage = func() { f.printUsage(out) }
 }
 
 func (f *FlagSet) SetVersion(ver string) { f.Version = sels(ver, f.Version) }
@@ -100,18 +112,34 @@ func (f *FlagSet) Parse(args []string) (err error) {
 		f.BoolP(versionFlag, shorthand, false, "显示版本信息")
 	}
 
-	if err = f.pFlagSet.Parse(args); err != nil {
+	if f.parent == nil && len(args) > 0 && args[0] == completeCmd {
+		f.printCompletions(args[1:])
 		return
 	}
 
+	if err = f.parseCommand(args); err != nil {
+		return
+	}
+
+	// 版本信息与帮助（由 pflag 在 parseCommand 中处理）不依赖配置文件与校验，在计算字段值之前处理
 	if ver, _ := f.GetBool(versionFlag); ver {
 		if f.HandleVersionFlag != nil {
 			f.HandleVersionFlag(f.Version)
-		} else {
-			fmt.Fprintf(os.Stdout, f.Version)
-			os.Exit(0)
+			return
 		}
+		fmt.Fprintf(os.Stdout, f.Version)
+		os.Exit(0)
+	}
+
+	if err = f.resolveLayers(); err != nil {
+		return
 	}
 
+	if f.parent == nil {
+		if err = f.printConfig(); err != nil {
+			return
+		}
+		err = f.checkRules()
+	}
 	return
 }
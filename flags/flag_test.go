@@ -0,0 +1,645 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hxnas/pkg/config"
+)
+
+func init() {
+	config.Registry("json", func(v any) config.ReadFunc {
+		return func(data []byte) error { return json.Unmarshal(data, v) }
+	}, ".json")
+	config.RegistryEncoder("json", func(v any) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }, ".json")
+}
+
+type layeredConfig struct {
+	Host  string `json:"host" default:"localhost"`
+	Port  int    `json:"port" env:"T_PORT" default:"80"`
+	Name  string `json:"name" default:"a"`
+	Token string `json:"token" validate:"required"`
+}
+
+func TestConfigLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"host":"cfg","port":81,"name":"cfg","token":"t"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_PORT", "82")
+
+	var cfg layeredConfig
+	f := New("test")
+	f.Struct(&cfg, nil)
+	f.Config("config", "c", "", "")
+
+	if err := f.Parse([]string{"--name", "cli", "--config", path}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := layeredConfig{Host: "cfg", Port: 82, Name: "cli", Token: "t"}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	for name, origin := range map[string]string{
+		"host":  "config " + path,
+		"port":  "env T_PORT",
+		"name":  "flag --name",
+		"token": "config " + path,
+	} {
+		if got := f.Origin(name).String(); got != origin {
+			t.Errorf("origin of %s: got %q, want %q", name, got, origin)
+		}
+	}
+
+	var missing layeredConfig
+	f = New("test")
+	f.Struct(&missing, nil)
+	f.Config("config", "c", filepath.Join(t.TempDir(), "none.json"), "")
+	if err := f.Parse(nil); err == nil {
+		t.Fatal("expected validation error for missing token")
+	}
+
+	var version string
+	f = New("test")
+	f.Version = "1.0.0"
+	f.HandleVersionFlag = func(v string) { version = v }
+	f.Struct(&layeredConfig{}, nil)
+	f.Config("config", "c", "", "")
+	if err := f.Parse([]string{"--version", "--config", filepath.Join(t.TempDir(), "none.json")}); err != nil || version != "1.0.0" {
+		t.Fatalf("--version must not load config or validate: %q %v", version, err)
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"host":"cfg"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_PORT", "82")
+
+	var cfg struct {
+		Host string `json:"host" default:"localhost"`
+		Port int    `json:"port" env:"T_PORT" default:"80"`
+		Name string `json:"name" default:"a"`
+		DB   struct {
+			Pass string `json:"pass" secret:"true" default:"p"`
+		} `json:"db"`
+	}
+
+	var dump string
+	f := New("test")
+	f.Struct(&cfg, nil)
+	f.Config("config", "c", "", "")
+	f.PrintConfig("print-config", "")
+	f.HandlePrintConfig = func(data []byte) { dump = string(data) }
+
+	if err := f.Parse([]string{"--name", "cli", "--config", path, "--print-config=json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		`"host": "cfg", // config ` + path,
+		`"port": 82, // env T_PORT`,
+		`"name": "cli", // flag --name`,
+		`"pass": "******" // default`,
+	} {
+		if !strings.Contains(dump, line) {
+			t.Errorf("dump missing %q:\n%s", line, dump)
+		}
+	}
+
+	if cfg.DB.Pass != "p" {
+		t.Fatalf("secret of the bound struct was modified: %q", cfg.DB.Pass)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	var global struct {
+		Verbose bool `flag:"verbose"`
+	}
+	var serve struct {
+		Port int `default:"80"`
+	}
+
+	var ran []string
+	f := New("tool")
+	f.Struct(&global, nil)
+	f.Persistent()
+
+	s := f.Command("serve", "start the server", func(context.Context) error {
+		ran = append(ran, "serve")
+		return nil
+	})
+	s.Struct(&serve, nil)
+	f.Command("backup", "run a backup", nil).Command("now", "backup now", func(context.Context) error {
+		ran = append(ran, "backup now")
+		return nil
+	})
+
+	if err := f.Execute(context.Background(), []string{"serve", "--port", "90", "--verbose", "extra"}); err != nil {
+		t.Fatal(err)
+	}
+	if !global.Verbose || serve.Port != 90 || f.Active() != s || s.Arg(0) != "extra" {
+		t.Fatalf("unexpected parse result: %+v %+v active=%s", global, serve, f.Active().commandPath())
+	}
+
+	if err := f.Execute(context.Background(), []string{"backup", "now"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ran, []string{"serve", "backup now"}) {
+		t.Fatalf("unexpected dispatch: %q", ran)
+	}
+
+	if err := f.Execute(context.Background(), []string{"restore"}); err == nil || !strings.Contains(err.Error(), `unknown command "restore"`) {
+		t.Fatalf("expected unknown command error, got %v", err)
+	}
+
+	var buf strings.Builder
+	s.printUsage(&buf)
+	for _, want := range []string{"tool serve\n", "start the server", "--port", "Global Flags:\n      --verbose"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("usage missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	var cfg struct {
+		Mode     string `validate:"oneof=ro rw"`
+		Data     string `complete:"dir"`
+		Verbose  bool
+		LogFile  string
+		CacheDir string
+		Profile  string
+	}
+
+	f := New("tool")
+	f.Struct(&cfg, nil)
+	f.Config("config", "c", "", "")
+	f.Persistent("config")
+	s := f.Command("serve", "start the server", nil)
+	s.String("listen", "", "listen address")
+	s.Complete("listen", func(prefix string) []string { return []string{":80", ":443"} })
+	f.Command("status", "show status\nmore", nil)
+
+	for _, tc := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{""}, []string{"serve\tstart the server", "status\tshow status", ":"}},
+		{[]string{"--mo"}, []string{"--mode\tMode (one of: ro|rw)", ":"}},
+		{[]string{"--mode", "r"}, []string{"ro", "rw", ":"}},
+		{[]string{"--mode=rw"}, []string{"--mode=rw", ":"}},
+		{[]string{"--verbose", "se"}, []string{"serve\tstart the server", ":"}},
+		{[]string{"--data", ""}, []string{":dir"}},
+		{[]string{"--logfile", ""}, []string{":file"}},
+		{[]string{"--cachedir", ""}, []string{":dir"}},
+		{[]string{"--profile", ""}, []string{":"}},
+		{[]string{"serve", "-c", "a"}, []string{":file"}},
+		{[]string{"serve", "--listen="}, []string{"--listen=:80", "--listen=:443", ":"}},
+		{[]string{"status", "x"}, []string{":file"}},
+	} {
+		if got := f.Completions(tc.args); !slices.Equal(got, tc.want) {
+			t.Errorf("Completions(%q): got %q, want %q", tc.args, got, tc.want)
+		}
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var buf strings.Builder
+		if err := f.Completion(&buf, shell); err != nil || !strings.Contains(buf.String(), "__tool_complete") {
+			t.Errorf("%s completion: %v\n%s", shell, err, buf.String())
+		}
+	}
+	if err := f.Completion(io.Discard, "tcsh"); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+
+	var lines []string
+	f.HandleCompletion = func(l []string) { lines = l }
+	if err := f.Parse([]string{"__complete", "--mode", "r"}); err != nil || !slices.Equal(lines, []string{"ro", "rw", ":"}) {
+		t.Errorf("__complete: got %q, %v", lines, err)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	t.Setenv("T_PORT", "8080")
+	t.Setenv("T_HOST", "example.com")
+
+	var cfg struct {
+		Port int    `env:"T_PORT" default:"80" usage:"listen port"`
+		Mode string `validate:"oneof=ro rw" deprecated:"replaced"`
+		Host string `env:"T_HOST" usage:"server host"`
+	}
+	cfg.Host = "localhost"
+
+	f := New("tool")
+	f.SetVersion("1.2.0")
+	f.SetDescription("Tool manages the box.")
+	f.Struct(&cfg, nil)
+	f.Persistent("port")
+	f.Command("serve", "start the server", nil).Bool("tls", false, "enable tls")
+
+	var man strings.Builder
+	if err := f.ManPage(&man); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`.TH "TOOL" "1" "" "tool 1.2.0" "tool Manual"`,
+		"tool \\- Tool manages the box.",
+		"\\fB\\-\\-port\\fR \\fIint\\fR\nlisten port\n.br\nDefault: 80",
+		"Values: ro, rw\n.br\nDeprecated: replaced",
+		".SH COMMANDS\n.TP\n\\fBserve\\fR",
+		".SH ENVIRONMENT\n.TP\n\\fBT_PORT\\fR",
+	} {
+		if !strings.Contains(man.String(), want) {
+			t.Errorf("man page missing %q:\n%s", want, man.String())
+		}
+	}
+
+	var md strings.Builder
+	if err := f.Markdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# tool\n\nTool manages the box.\n\nVersion: `1.2.0`",
+		"| `--port int` | `T_PORT` | listen port<br>Default: 80 |",
+		"| `--host string` | `T_HOST` | server host<br>Default: localhost |",
+		"- [serve](#tool-serve) - start the server",
+		"## tool serve",
+		"### Global Flags\n\n| Flag | Env | Description |\n| --- | --- | --- |\n| `--port int` |",
+		"| `--tls` |  | enable tls |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+}
+
+func TestMapField(t *testing.T) {
+	t.Setenv("T_LABELS", "a=1, b=2")
+
+	var cfg struct {
+		Labels map[string]string `flag:"label" env:"T_LABELS"`
+		Quota  map[string]int    `default:"x=1,y=2"`
+	}
+	f := New("test")
+	f.Struct(&cfg, nil)
+
+	if err := f.Parse([]string{"--label", "c=3", "--label", "hosts=a,b", "--quota", "y=3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := map[string]string{"c": "3", "hosts": "a,b"}; !maps.Equal(cfg.Labels, want) {
+		t.Fatalf("labels: got %v, want %v", cfg.Labels, want)
+	}
+	if want := map[string]int{"y": 3}; !maps.Equal(cfg.Quota, want) {
+		t.Fatalf("quota: got %v, want %v", cfg.Quota, want)
+	}
+
+	args, err := FieldsToArgs(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"--label", "c=3", "--label", "hosts=a,b", "--quota", "y=3"}; !slices.Equal(args, want) {
+		t.Fatalf("args: got %q, want %q", args, want)
+	}
+
+	var back struct {
+		Labels map[string]string `flag:"label"`
+		Quota  map[string]int
+	}
+	fb := New("back")
+	fb.Struct(&back, nil)
+	if err = fb.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(back.Labels, cfg.Labels) || !maps.Equal(back.Quota, cfg.Quota) {
+		t.Fatalf("round trip: got %v %v, want %v %v", back.Labels, back.Quota, cfg.Labels, cfg.Quota)
+	}
+
+	f = New("test")
+	f.Struct(&cfg, nil)
+	if err = f.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"a": "1", "b": "2"}; !maps.Equal(cfg.Labels, want) {
+		t.Fatalf("env labels: got %v, want %v", cfg.Labels, want)
+	}
+
+	if err = f.Parse([]string{"--label", "k"}); err == nil {
+		t.Fatal("expected error for entry without =")
+	}
+}
+
+func TestEnumField(t *testing.T) {
+	type enumConfig struct {
+		Level    string   `enum:"debug|info|warn" env:"T_LEVEL" default:"info"`
+		Compress []string `choices:"gzip,zstd"`
+	}
+
+	var cfg enumConfig
+	f := New("test")
+	f.Struct(&cfg, nil)
+
+	if err := f.Parse([]string{"--level", "warn", "--compress", "zstd"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != "warn" || !slices.Equal(cfg.Compress, []string{"zstd"}) {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	if usage := f.Lookup("level").Usage; !strings.Contains(usage, "(one of: debug|info|warn)") {
+		t.Errorf("usage: %q", usage)
+	}
+	if got := f.Completions([]string{"--level", "d"}); !slices.Equal(got, []string{"debug", ":"}) {
+		t.Errorf("completions: %q", got)
+	}
+
+	f = New("test")
+	f.Struct(&enumConfig{}, nil)
+	if err := f.Parse([]string{"--compress", "lz4"}); err == nil || !strings.Contains(err.Error(), "--compress") || !strings.Contains(err.Error(), `"lz4" is not one of gzip|zstd`) {
+		t.Errorf("flag error: %v", err)
+	}
+
+	t.Setenv("T_LEVEL", "trace")
+	f = New("test")
+	f.Struct(&enumConfig{}, nil)
+	if err := f.Parse(nil); err == nil || !strings.Contains(err.Error(), "env T_LEVEL (--level)") {
+		t.Errorf("env error: %v", err)
+	}
+}
+
+func TestRules(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `requires:"key"`
+		Key  string
+	}
+	type ruleConfig struct {
+		Token    string `required:"true" env:"T_TOKEN"`
+		User     string `exclusive:"auth" anyof:"login"`
+		Password string `exclusive:"auth" anyof:"login" env:"T_PASSWORD"`
+		Keyfile  string `anyof:"login"`
+		TLS      tlsConfig
+	}
+
+	parse := func(args ...string) error {
+		f := New("test")
+		f.Struct(&ruleConfig{}, nil)
+		return f.Parse(args)
+	}
+
+	if err := parse("--token", "t", "--user", "u", "--tls.cert", "c", "--tls.key", "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("T_PASSWORD", "p")
+	err := parse("--user", "u", "--tls.cert", "c")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	for _, want := range []string{
+		"--token (env: T_TOKEN, T_TOKEN_FILE) is required",
+		"--user, --password are mutually exclusive, got --user from flag --user and --password from env T_PASSWORD",
+		"--tls.cert requires --tls.key",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+
+	t.Setenv("T_PASSWORD", "")
+	if err = parse("--token", "t"); err == nil || err.Error() != "at least one of --user, --password (env: T_PASSWORD, T_PASSWORD_FILE), --keyfile is required" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExtendTypes(t *testing.T) {
+	var cfg struct {
+		Cache  Size           `default:"512MiB"`
+		Quota  Size           `default:"2G"`
+		Disk   Size           `default:"1.5TB"`
+		Mode   fs.FileMode    `default:"0755"`
+		Proxy  *url.URL       `default:"http://nas.local:3128/"`
+		Filter *regexp.Regexp `default:"^[a-z]+$"`
+		Allow  []netip.Prefix `default:"10.0.0.0/8"`
+		Listen netip.AddrPort `default:":8080"`
+	}
+
+	f := New("test")
+	f.Struct(&cfg, nil)
+	if err := f.Parse([]string{"--allow", "192.168.1.0/24"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Cache != 512<<20 || cfg.Quota != 2<<30 || cfg.Disk != 1_500_000_000_000 || cfg.Mode != 0755 || cfg.Proxy.Host != "nas.local:3128" || !cfg.Filter.MatchString("abc") || cfg.Listen.Port() != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	args, err := FieldsToArgs(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--cache", "512MiB", "--quota", "2GiB", "--disk", "1500GB", "--mode", "0755",
+		"--proxy", "http://nas.local:3128/", "--filter", "^[a-z]+$",
+		"--allow", "192.168.1.0/24", "--listen", "0.0.0.0:8080",
+	}
+	if !slices.Equal(args, want) {
+		t.Fatalf("args: got %q, want %q", args, want)
+	}
+
+	if got := f.Lookup("mode").Value.Type(); got != "mode" {
+		t.Errorf("type of --mode: %q", got)
+	}
+
+	for _, s := range []string{"12XB", "1.2.3K", "-1G", "8192PiB", "9223372036854775808"} {
+		if _, err = rParseSize(s); err == nil {
+			t.Errorf("expected error for size %q", s)
+		}
+	}
+
+	if n, err := rParseSize("9007199254740993"); err != nil || n != 9007199254740993 {
+		t.Errorf("integer size lost precision: %d %v", n, err)
+	}
+}
+
+type csvValue []string
+
+func (c *csvValue) Set(s string) error { *c = append(*c, strings.Split(s, ",")...); return nil }
+func (c *csvValue) String() string     { return strings.Join(*c, ",") }
+func (c *csvValue) Type() string       { return "csv" }
+
+func TestTextTypes(t *testing.T) {
+	var cfg struct {
+		Level slog.Level  `default:"warn"`
+		Peer  *netip.Addr `default:"10.0.0.1"`
+		Tags  csvValue    `default:"a,b"`
+		Log   []slog.Level
+	}
+
+	f := New("test")
+	f.Struct(&cfg, nil)
+	if err := f.Parse([]string{"--log", "debug", "--log", "error"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Level != slog.LevelWarn || cfg.Peer.String() != "10.0.0.1" || !slices.Equal(cfg.Tags, csvValue{"a", "b"}) {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	if got := f.Lookup("tags").Value.Type(); got != "csv" {
+		t.Errorf("type of --tags: %q", got)
+	}
+
+	args, err := FieldsToArgs(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--level", "WARN", "--peer", "10.0.0.1", "--tags", "a,b", "--log", "DEBUG", "--log", "ERROR"}
+	if !slices.Equal(args, want) {
+		t.Fatalf("args: got %q, want %q", args, want)
+	}
+
+	if err = f.Parse([]string{"--level", "loud"}); err == nil {
+		t.Fatal("expected error for invalid level")
+	}
+}
+
+func TestEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("T_DB_PASSWORD_FILE", path)
+	t.Setenv("T_DB_USER", "admin")
+
+	var cfg struct {
+		User     string `env:"T_DB_USER" default:"root"`
+		Password string `env:"T_DB_PASSWORD" default:"changeme"`
+	}
+	f := New("test")
+	f.Struct(&cfg, nil)
+	if err := f.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.User != "admin" || cfg.Password != "s3cret" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if got := f.Origin("password").String(); got != "env T_DB_PASSWORD_FILE" {
+		t.Errorf("origin: %q", got)
+	}
+
+	fl := f.Lookup("password")
+	if fl.DefValue != "changeme" || !strings.Contains(fl.Usage, "(env: T_DB_PASSWORD, T_DB_PASSWORD_FILE)") {
+		t.Errorf("default %q, usage %q", fl.DefValue, fl.Usage)
+	}
+
+	t.Setenv("T_DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	f = New("test")
+	f.Struct(&cfg, nil)
+	if err := f.Parse(nil); err == nil || !strings.Contains(err.Error(), "env T_DB_PASSWORD_FILE (--password)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSecretField(t *testing.T) {
+	t.Setenv("T_API_KEY", "k3y")
+
+	var cfg struct {
+		User     string        `default:"admin"`
+		Password string        `secret:"true" env:"T_PASSWORD" default:"changeme"`
+		APIKey   config.Secret `flag:"api-key" env:"T_API_KEY"`
+		Salt     string        `secret:"true"`
+	}
+	f := New("test")
+	f.Struct(&cfg, nil)
+	if err := f.Parse([]string{"--password", "p@ss"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"password", "api-key"} {
+		fl := f.Lookup(name)
+		if fl.DefValue != "******" {
+			t.Errorf("default of --%s: %q", name, fl.DefValue)
+		}
+		if got := fl.Value.(*Value).LogValue().String(); got != "******" {
+			t.Errorf("log value of --%s: %q", name, got)
+		}
+	}
+
+	args, err := FieldsToArgs(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"--user", "admin"}; !slices.Equal(args, want) {
+		t.Fatalf("args: got %q, want %q", args, want)
+	}
+
+	cmd := exec.Command("app")
+	cmd.Env = []string{"T_PASSWORD=old"}
+	if err = FieldsToCmd(cmd, &cfg, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"app", "--user", "admin"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("args: got %q, want %q", cmd.Args, want)
+	}
+	if want := []string{"T_PASSWORD=old", "T_PASSWORD=p@ss", "T_API_KEY=k3y"}; !slices.Equal(cmd.Env, want) {
+		t.Errorf("env: got %q, want %q", cmd.Env, want)
+	}
+
+	cmd = exec.Command("app")
+	cmd.Env = []string{"T_PASSWORD=old"}
+	if err = FieldsToCmd(cmd, &cfg, true); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"T_PASSWORD_FILE=/dev/fd/3", "T_API_KEY_FILE=/dev/fd/4"}; !slices.Equal(cmd.Env, want) {
+		t.Errorf("env: got %q, want %q", cmd.Env, want)
+	}
+	for i, want := range []string{"p@ss", "k3y"} {
+		data, _ := io.ReadAll(cmd.ExtraFiles[i])
+		cmd.ExtraFiles[i].Close()
+		if string(data) != want {
+			t.Errorf("fd %d: got %q, want %q", 3+i, data, want)
+		}
+	}
+
+	cfg.Salt = "x"
+	if err = FieldsToCmd(exec.Command("app"), &cfg, false); err == nil || !strings.Contains(err.Error(), "secret --salt") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	for _, ptr := range []any{
+		&struct {
+			Keys []string `secret:"true" env:"T_KEYS"`
+		}{},
+		&struct {
+			Keys []config.Secret `env:"T_KEYS"`
+		}{},
+		&struct {
+			Pin int `secret:"true"`
+		}{},
+	} {
+		f = New("test")
+		f.Struct(ptr, nil)
+		if err = f.Parse(nil); err == nil || !strings.Contains(err.Error(), "must be a single string") {
+			t.Errorf("%T: expected error for non-string secret, got %v", ptr, err)
+		}
+	}
+}
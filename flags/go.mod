@@ -1,5 +1,10 @@
 module github.com/hxnas/pkg/flags
 
-go 1.20
+go 1.22.3
 
-require github.com/spf13/pflag v1.0.5
+require (
+	github.com/hxnas/pkg/config v0.0.0-00010101000000-000000000000
+	github.com/spf13/pflag v1.0.5
+)
+
+replace github.com/hxnas/pkg/config => ../config
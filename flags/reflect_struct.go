@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/hxnas/pkg/config"
 )
 
+var secretType = reflect.TypeOf(config.Secret(""))
+
 type FlagField struct {
 	Field           reflect.StructField
 	Name            string
@@ -18,13 +23,24 @@ type FlagField struct {
 	Env             []string
 	Deprecated      string
 	ShortDeprecated string
+	Enum            []string // 可选值，来自 enum（或 choices）标签，未设置时取 validate 标签的 oneof 规则
+	Complete        string   // 补全提示，file、dir 或 file:yaml,yml
+	Required        bool     // 必须设置，来自 required 标签
+	Exclusive       []string // 所属的互斥组，同组参数最多设置一个
+	AnyOf           []string // 所属的必选组，同组参数至少设置一个
+	Requires        []string // 设置该参数时必须同时设置的参数
+	Secret          bool     // 敏感值，来自 secret 标签或 config.Secret 类型，只能是单个字符串
 
-	defTag string
+	defTag  string
+	initial []string // 结构体字段的初始值
 }
 
 func (f *FlagField) applyPrefix(prefix *Prefix) *FlagField {
 	if prefix != nil {
 		f.Name = prefix.Flag + f.Name
+		for i, name := range f.Requires {
+			f.Requires[i] = prefix.Flag + name
+		}
 		for i, env := range f.Env {
 			f.Env[i] = prefix.Env + env
 		}
@@ -33,18 +49,67 @@ func (f *FlagField) applyPrefix(prefix *Prefix) *FlagField {
 }
 
 func (f *FlagField) applyDefault() (err error) {
-	for _, k := range f.Env {
-		if s := os.Getenv(strings.TrimSpace(k)); s != "" {
-			if f.defTag != "" {
-				f.Value.defs = o2s(f.defTag)
-			}
-			err = f.Value.SetString(o2s(s), true, true, false)
-			return
+	k, s, fromFile, err := f.lookupEnv()
+	if err != nil {
+		return fmt.Errorf("env %s (--%s): %w", k, f.Name, err)
+	}
+
+	if s != "" {
+		if f.defTag != "" {
+			f.Value.defs = f.Value.split(f.defTag)
+		}
+
+		// 从文件读取的通常是密钥，不作为默认值显示在帮助信息中
+		if err = f.Value.SetString(f.Value.split(s), true, !fromFile, true); err != nil {
+			return fmt.Errorf("env %s (--%s): %w", k, f.Name, err)
 		}
+		f.Value.origin = Origin{Layer: LayerEnv, Key: k}
+		return
 	}
 
 	if f.defTag != "" {
-		err = f.Value.SetString(o2s(f.defTag), true, true, true)
+		if err = f.Value.SetString(f.Value.split(f.defTag), true, true, true); err != nil {
+			return fmt.Errorf("default of --%s: %w", f.Name, err)
+		}
+	}
+	return
+}
+
+// defaults 返回 default 标签或结构体初始值，不受环境变量影响
+func (f *FlagField) defaults() []string {
+	if f.defTag != "" {
+		return f.Value.split(f.defTag)
+	}
+	return f.initial
+}
+
+// envFileSuffix 环境变量未设置时，从 <ENV>_FILE 指定的文件读取值，如 Docker secrets
+const envFileSuffix = "_FILE"
+
+// lookupEnv 返回第一个有值的环境变量，环境变量为空时读取 <ENV>_FILE 指定的文件（去除首尾空白），此时 fromFile 为 true
+func (f *FlagField) lookupEnv() (key, value string, fromFile bool, err error) {
+	for _, k := range f.Env {
+		if k = strings.TrimSpace(k); k == "" {
+			continue
+		}
+
+		if value = os.Getenv(k); value != "" {
+			return k, value, false, nil
+		}
+
+		if path := os.Getenv(k + envFileSuffix); path != "" {
+			var data []byte
+			data, err = os.ReadFile(path)
+			return k + envFileSuffix, strings.TrimSpace(string(data)), true, err
+		}
+	}
+	return "", "", false, nil
+}
+
+// envs 返回可以设置该参数的环境变量，包括对应的 <ENV>_FILE
+func (f *FlagField) envs() (envs []string) {
+	for _, k := range f.Env {
+		envs = append(envs, k, k+envFileSuffix)
 	}
 	return
 }
@@ -107,6 +172,33 @@ func ParseStruct(src any, prefix *Prefix) (fields []*FlagField, err error) {
 
 var ErrSkip = errors.New("skip parse this field")
 
+// pathHint 按字段类型与名称推断路径补全提示：类型名或字段名为 File、Path（或以其结尾）的字符串字段补全文件，
+// 为 Dir（或以其结尾）的补全目录
+func pathHint(f reflect.StructField) string {
+	t := typeIndirect(f.Type)
+	if t.Kind() == reflect.Slice {
+		t = typeIndirect(t.Elem())
+	}
+	if t.Kind() != reflect.String {
+		return ""
+	}
+
+	for _, name := range []string{t.Name(), f.Name} {
+		switch {
+		case isWordSuffix(name, "Dir"):
+			return "dir"
+		case isWordSuffix(name, "File"), isWordSuffix(name, "Path"):
+			return "file"
+		}
+	}
+	return ""
+}
+
+// isWordSuffix 判断驼峰命名的 name 是否等于 word 或以 word 结尾，如 DataDir，但不包括 Profile
+func isWordSuffix(name, word string) bool {
+	return strings.EqualFold(name, word) || strings.HasSuffix(name, word)
+}
+
 func parseField(r reflect.Value, f reflect.StructField, fieldIndex int) (item FlagField, err error) {
 	if item.Name, item.Shorthand, item.Env, err = parseTag(f, false); err != nil {
 		return
@@ -114,8 +206,40 @@ func parseField(r reflect.Value, f reflect.StructField, fieldIndex int) (item Fl
 
 	item.Field = f
 	item.Value = newValue(r.Field(fieldIndex))
+	item.initial = item.Value.defs
 	item.Usage = getTag(f.Tag, _TAG_USAGE)
 	item.defTag = getTag(f.Tag, _TAG_DEFAULT)
+	item.Complete = sels(getTag(f.Tag, _TAG_COMPLETE), pathHint(f))
+
+	if enum := sels(getTag(f.Tag, _TAG_ENUM), getTag(f.Tag, _TAG_CHOICES)); enum != "" {
+		item.Enum = strings.FieldsFunc(enum, func(r rune) bool { return r == '|' || r == ',' || unicode.IsSpace(r) })
+	} else {
+		for _, rule := range config.ParseRules(getTag(f.Tag, _TAG_VALIDATE)) {
+			if rule.Name == "oneof" {
+				item.Enum = strings.Fields(rule.Arg)
+			}
+		}
+	}
+	item.Value.enum = item.Enum
+
+	item.Required, _ = strconv.ParseBool(getTag(f.Tag, _TAG_REQUIRED))
+	item.Exclusive = fieldSpilt(getTag(f.Tag, _TAG_EXCLUSIVE))
+	item.AnyOf = fieldSpilt(getTag(f.Tag, _TAG_ANYOF))
+	item.Requires = fieldSpilt(getTag(f.Tag, _TAG_REQUIRES))
+
+	ft := typeIndirect(f.Type)
+	item.Secret = config.IsSecret(f)
+	item.Secret = item.Secret || ft == secretType
+	if k := ft.Kind(); k == reflect.Slice || k == reflect.Array || k == reflect.Map {
+		item.Secret = item.Secret || typeIndirect(ft.Elem()) == secretType
+	}
+	item.Value.secret = item.Secret
+
+	// 敏感值通过单个环境变量传给子进程，打印配置时按字符串隐藏，多值或非字符串类型无法保证不泄露
+	if item.Secret && ft.Kind() != reflect.String {
+		err = fmt.Errorf("secret field %s must be a single string, got %s", f.Name, f.Type)
+		return
+	}
 
 	if deprecatedTag := getTag(f.Tag, _TAG_DEPRECATED); deprecatedTag != "" {
 		nn := fieldSpilt(deprecatedTag)
@@ -204,20 +328,19 @@ const (
 	_TAG_ENV        = "env"
 	_TAG_USAGE      = "usage"
 	_TAG_DEFAULT    = "default"
+	_TAG_VALIDATE   = "validate"
+	_TAG_COMPLETE   = "complete"
+	_TAG_ENUM       = "enum"
+	_TAG_CHOICES    = "choices"
+	_TAG_REQUIRED   = "required"
+	_TAG_EXCLUSIVE  = "exclusive"
+	_TAG_ANYOF      = "anyof"
+	_TAG_REQUIRES   = "requires"
+	_TAG_SECRET     = "secret"
 )
 
+// 标签的拆分规则与 config 包一致
 var (
-	fieldSpilt = func(s string) []string {
-		fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '|' || unicode.IsSpace(r) })
-		var x int
-		for _, s := range fields {
-			if s = strings.Trim(s, "-_"); s != "" {
-				fields[x] = s
-				x++
-			}
-		}
-		return fields[:x]
-	}
-
-	getTag = func(tag reflect.StructTag, tagName string) string { return strings.TrimSpace(tag.Get(tagName)) }
+	fieldSpilt = config.SplitTag
+	getTag     = config.GetTag
 )
@@ -1,8 +1,11 @@
 package flags
 
 import (
+	"encoding"
 	"reflect"
 	"strings"
+
+	"github.com/spf13/pflag"
 )
 
 func rType(t reflect.Type, noExtend ...bool) string {
@@ -12,11 +15,19 @@ func rType(t reflect.Type, noExtend ...bool) string {
 		}
 	}
 
+	if isText(t) {
+		if fv, ok := reflect.New(t).Interface().(pflag.Value); ok {
+			return fv.Type()
+		}
+	}
+
 	switch kind := t.Kind(); kind {
 	case reflect.Pointer:
 		return rType(t.Elem(), noExtend...)
 	case reflect.Slice:
 		return rType(t.Elem(), noExtend...) + "s"
+	case reflect.Map:
+		return rType(t.Key(), noExtend...) + "=" + rType(t.Elem(), noExtend...)
 	default:
 		s := t.String()
 		for i := len(s) - 1; i >= 0 && s[i] != '/'; i-- {
@@ -31,7 +42,18 @@ func rType(t reflect.Type, noExtend ...bool) string {
 // 判断类型是否基础类型: int*, uint*, float*, string, bool
 func isBasic(t reflect.Type) bool { return isBasicKind(t.Kind()) }
 
-func isKnown(t reflect.Type) bool { return IsExtend(t) || isBasic(t) }
+func isKnown(t reflect.Type) bool { return IsExtend(t) || isText(t) || isBasic(t) }
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	pflagValueType      = reflect.TypeFor[pflag.Value]()
+)
+
+// isText 判断类型是否可通过 encoding.TextUnmarshaler 或 pflag.Value 从字符串设置（含指针接收者的实现）
+func isText(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(pflagValueType)
+}
 
 func isAllow(in reflect.Type) bool {
 	var checkTypeInternal func(t reflect.Type, p, s bool) bool
@@ -44,6 +66,8 @@ func isAllow(in reflect.Type) bool {
 			return p && checkTypeInternal(t.Elem(), false, s)
 		case reflect.Slice:
 			return (s && checkTypeInternal(t.Elem(), true, false))
+		case reflect.Map:
+			return s && isKnown(t.Key()) && isKnown(t.Elem())
 		default:
 			return false
 		}
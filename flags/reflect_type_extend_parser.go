@@ -2,8 +2,12 @@ package flags
 
 import (
 	"fmt"
+	"io/fs"
+	"math"
 	"net"
 	"net/netip"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,8 +17,19 @@ func init() {
 	Extend(rParseTime, rFormatTime, "time")
 	Extend(rParseIp, rFormatIp, "ip")
 	Extend(rParseDuration, rFormatDuration, "duration")
+	Extend(rParseSize, rFormatSize, "size")
+	Extend(rParseFileMode, rFormatFileMode, "mode")
+	Extend(url.Parse, rFormatURL, "url")
+	Extend(regexp.Compile, rFormatRegexp, "regexp")
+	Extend(netip.ParsePrefix, netip.Prefix.String, "cidr")
+	Extend(rParseAddrPort, netip.AddrPort.String, "addr")
 }
 
+// Size 字节大小，如 512MiB、2G，K/M/G/T/P 及 KiB 等带 i 的单位按 1024 进制，KB/MB 等按 1000 进制
+type Size int64
+
+func (s Size) String() string { return rFormatSize(s) }
+
 func rParseTime(s string) (t time.Time, err error) {
 	if s != "" {
 		var allowTimeLayouts = []string{
@@ -98,3 +113,111 @@ func rFormatIp(in net.IP) (s string) {
 	}
 	return
 }
+
+var (
+	sizeRe    = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+	sizeUnits = []string{"", "K", "M", "G", "T", "P"}
+)
+
+func rParseSize(in string) (n Size, err error) {
+	if in = strings.TrimSpace(in); in != "" {
+		m := sizeRe.FindStringSubmatch(in)
+		if m == nil {
+			return 0, fmt.Errorf("invalid size: %s", in)
+		}
+
+		unit, base := strings.ToUpper(m[2]), 1024.0
+		switch {
+		case unit == "B":
+			unit = ""
+		case strings.HasSuffix(unit, "IB"), strings.HasSuffix(unit, "I"):
+			unit = unit[:1]
+		case len(unit) == 2 && unit[1] == 'B':
+			unit, base = unit[:1], 1000 // KB、MB 等不带 i 的单位
+		}
+
+		exp := -1
+		for i, u := range sizeUnits {
+			if u == unit {
+				exp = i
+			}
+		}
+		if exp < 0 {
+			return 0, fmt.Errorf("invalid size unit: %s", m[2])
+		}
+
+		mult := int64(math.Pow(base, float64(exp)))
+
+		// 整数直接按 int64 计算，避免超过 2^53 时 float64 丢失精度
+		if i, e := strconv.ParseInt(m[1], 10, 64); e == nil {
+			if i > math.MaxInt64/mult {
+				return 0, fmt.Errorf("size out of range: %s", in)
+			}
+			return Size(i * mult), nil
+		}
+
+		f, _ := strconv.ParseFloat(m[1], 64)
+		if f *= float64(mult); f >= math.MaxInt64 {
+			return 0, fmt.Errorf("size out of range: %s", in)
+		}
+		n = Size(f)
+	}
+	return
+}
+
+func rFormatSize(in Size) (s string) {
+	if in != 0 {
+		for exp := len(sizeUnits) - 1; exp > 0; exp-- {
+			if bin := Size(1) << (10 * exp); in%bin == 0 {
+				return strconv.FormatInt(int64(in/bin), 10) + sizeUnits[exp] + "iB"
+			}
+			if dec := Size(math.Pow(1000, float64(exp))); in%dec == 0 {
+				return strconv.FormatInt(int64(in/dec), 10) + sizeUnits[exp] + "B"
+			}
+		}
+		s = strconv.FormatInt(int64(in), 10)
+	}
+	return
+}
+
+func rParseFileMode(s string) (m fs.FileMode, err error) {
+	if s != "" {
+		var n uint64
+		if n, err = strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0o"), 8, 32); err == nil {
+			m = fs.FileMode(n)
+		}
+	}
+	return
+}
+
+func rFormatFileMode(in fs.FileMode) (s string) {
+	if in != 0 {
+		s = "0" + strconv.FormatUint(uint64(in), 8)
+	}
+	return
+}
+
+func rFormatURL(in *url.URL) (s string) {
+	if in != nil {
+		s = in.String()
+	}
+	return
+}
+
+func rFormatRegexp(in *regexp.Regexp) (s string) {
+	if in != nil {
+		s = in.String()
+	}
+	return
+}
+
+// rParseAddrPort 解析 ip:port，省略 ip 时（如 :8080）监听所有 IPv4 地址
+func rParseAddrPort(s string) (r netip.AddrPort, err error) {
+	if s != "" {
+		if strings.HasPrefix(s, ":") {
+			s = netip.IPv4Unspecified().String() + s
+		}
+		r, err = netip.ParseAddrPort(s)
+	}
+	return
+}
@@ -1,9 +1,14 @@
 package flags
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
 )
 
 func Ref(src any) reflect.Value {
@@ -27,6 +32,12 @@ func rGet(v reflect.Value) (out []string) {
 		return o2s(te.Get(v))
 	}
 
+	if isText(v.Type()) {
+		if s, ok := textGet(v); ok {
+			return o2s(s)
+		}
+	}
+
 	switch kind := v.Kind(); kind {
 	case reflect.Pointer:
 		out = rGet(v.Elem())
@@ -34,6 +45,11 @@ func rGet(v reflect.Value) (out []string) {
 		for i := 0; i < v.Len(); i++ {
 			out = append(out, rGet(v.Index(i))...)
 		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			out = append(out, sel(rGet(k))+"="+sel(rGet(v.MapIndex(k))))
+		}
+		slices.Sort(out)
 	case reflect.String:
 		out = o2s(v.String())
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -61,6 +77,10 @@ func rSet(v reflect.Value, s string, reset bool) (err error) {
 		return te.Set(v, s)
 	}
 
+	if isText(v.Type()) {
+		return textSet(v.Addr(), s)
+	}
+
 	switch kind := v.Kind(); kind {
 	case reflect.String:
 		v.SetString(s)
@@ -107,6 +127,29 @@ func rSet(v reflect.Value, s string, reset bool) (err error) {
 		}
 
 		v.Set(reflect.Append(v, el))
+	case reflect.Map:
+		if reset || v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		// 每次设置一个 k=v，值中可以包含逗号，重复设置时追加
+		if s = strings.TrimSpace(s); s == "" {
+			return
+		}
+
+		k, val, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("invalid map entry %q, expected key=value", s)
+		}
+
+		kv, ev := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		if err = rSet(kv, strings.TrimSpace(k), false); err != nil {
+			return
+		}
+		if err = rSet(ev, val, false); err != nil {
+			return
+		}
+		v.SetMapIndex(kv, ev)
 	default:
 		err = fmt.Errorf("unknown kind: %s", kind)
 	}
@@ -114,6 +157,38 @@ func rSet(v reflect.Value, s string, reset bool) (err error) {
 	return
 }
 
+// textSet 通过 pflag.Value 或 encoding.TextUnmarshaler 设置值，p 为值的指针
+func textSet(p reflect.Value, s string) error {
+	switch x := p.Interface().(type) {
+	case pflag.Value:
+		return x.Set(s)
+	case encoding.TextUnmarshaler:
+		return x.UnmarshalText([]byte(s))
+	default:
+		return fmt.Errorf("can't set %s from text", p.Type().Elem())
+	}
+}
+
+// textGet 通过 pflag.Value、encoding.TextMarshaler 或 fmt.Stringer 格式化值，都未实现时 ok 为 false
+func textGet(v reflect.Value) (s string, ok bool) {
+	if !v.CanAddr() {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+
+	switch x := v.Addr().Interface().(type) {
+	case pflag.Value:
+		return x.String(), true
+	case encoding.TextMarshaler:
+		b, err := x.MarshalText()
+		return string(b), err == nil
+	case fmt.Stringer:
+		return x.String(), true
+	}
+	return
+}
+
 // IsZero reports whether v is the zero value for its type.
 //
 //	It return true if the argument is invalid.
@@ -1,37 +1,63 @@
 package flags
 
 import (
+	"fmt"
+	"log/slog"
 	"reflect"
+	"slices"
 	"strings"
+
+	"github.com/hxnas/pkg/config"
 )
 
 type Value struct {
-	Ref  reflect.Value //引用对象
-	typ  reflect.Type  //引用类型
-	defs []string      //默认值字符串
+	Ref    reflect.Value //引用对象
+	typ    reflect.Type  //引用类型
+	defs   []string      //默认值字符串
+	enum   []string      //可选值，为空时不限制
+	secret bool          //敏感值，在帮助信息与日志中显示为 ******
+
+	args   []string //命令行传入的值
+	origin Origin   //当前值的来源
 }
 
 func newValue(v reflect.Value) *Value { return &Value{Ref: v, typ: v.Type(), defs: rGet(v)} }
 
 func (v *Value) Type() string { return rType(v.DirectType()) }
 
-func (v *Value) Set(s string) (err error) { return v.SetString(o2s(s), false, false, true) }
+func (v *Value) Set(s string) (err error) {
+	if err = v.SetString(o2s(s), false, false, true); err == nil {
+		v.args = append(v.args, s)
+	}
+	return
+}
+
+func (v *Value) String() string { return v.format(v.defs) }
+
+// format 按值的类型格式化 defs，敏感值显示为 ******
+func (v *Value) format(defs []string) string {
+	if len(defs) > 0 {
+		if v.secret {
+			return config.Secret(defs[0]).String()
+		}
 
-func (v *Value) String() string {
-	if len(v.defs) > 0 {
-		if v.IsKind(reflect.Slice) {
-			return "[" + strings.Join(v.defs, ",") + "]"
+		if v.IsKind(reflect.Slice) || v.IsKind(reflect.Map) {
+			return "[" + strings.Join(defs, ",") + "]"
 		} else {
-			return v.defs[0]
+			return defs[0]
 		}
 	}
 	return ""
 }
 
 func (v *Value) SetString(args []string, reset, asDefault, refSync bool) (err error) {
+	if err = v.check(args); err != nil {
+		return
+	}
+
 	if refSync {
-		for _, arg := range args {
-			if err = rSet(v.Ref, arg, reset); err != nil {
+		for i, arg := range args {
+			if err = rSet(v.Ref, arg, reset && i == 0); err != nil {
 				return
 			}
 		}
@@ -43,5 +69,57 @@ func (v *Value) SetString(args []string, reset, asDefault, refSync bool) (err er
 	return
 }
 
+// LogValue 实现 slog.LogValuer，敏感值显示为 ******
+func (v *Value) LogValue() slog.Value {
+	s := strings.Join(rGet(v.Ref), ",")
+	if v.secret {
+		return config.Secret(s).LogValue()
+	}
+	return slog.StringValue(s)
+}
+
+// reset 将引用对象重置为零值后依次设置 args
+func (v *Value) reset(args []string) (err error) {
+	if err = v.check(args); err != nil {
+		return
+	}
+
+	v.Ref.Set(reflect.Zero(v.typ))
+	for _, arg := range args {
+		if err = rSet(v.Ref, arg, false); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// split 拆分来自环境变量或 default 标签的值，map 类型支持 k1=v1,k2=v2 的写法，其他类型整体作为一个值
+func (v *Value) split(s string) []string {
+	if !v.IsKind(reflect.Map) {
+		return o2s(s)
+	}
+	var out []string
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair != "" {
+			out = append(out, pair)
+		}
+	}
+	return out
+}
+
+// check 校验 args 是否均为可选值，map 类型不校验
+func (v *Value) check(args []string) error {
+	if len(v.enum) == 0 || v.IsKind(reflect.Map) {
+		return nil
+	}
+
+	for _, arg := range args {
+		if !slices.Contains(v.enum, arg) {
+			return fmt.Errorf("%q is not one of %s", arg, strings.Join(v.enum, "|"))
+		}
+	}
+	return nil
+}
+
 func (v *Value) DirectType() reflect.Type      { return typeIndirect(v.typ) }
 func (v *Value) IsKind(kind reflect.Kind) bool { return v.DirectType().Kind() == kind }
@@ -1,8 +1,13 @@
 package flags
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
 	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -21,6 +26,9 @@ func StructBind(flag *FlagSet, structPtr any, prefix *Prefix) (err error) {
 		return
 	}
 
+	flag.fields = append(flag.fields, fields...)
+	flag.structs = append(flag.structs, structPtr)
+
 	for _, field := range fields {
 		if err = field.applyDefault(); err != nil {
 			return
@@ -31,14 +39,23 @@ func StructBind(flag *FlagSet, structPtr any, prefix *Prefix) (err error) {
 			usage = field.Field.Name
 		}
 
+		if field.Required {
+			usage += " (required)"
+		}
+
+		if len(field.Enum) > 0 {
+			usage += fmt.Sprintf(" (one of: %s)", strings.Join(field.Enum, "|"))
+		}
+
 		if len(field.Env) > 0 {
-			usage += fmt.Sprintf(" (env: %s)", strings.Join(field.Env, ", "))
+			usage += fmt.Sprintf(" (env: %s)", strings.Join(field.envs(), ", "))
 		}
 
 		// 创建并配置命令行参数项
 		item := flag.VarPF(field.Value, field.Name, field.Shorthand, usage)
 		item.Deprecated = field.Deprecated               // 设置字段的弃用信息
 		item.ShorthandDeprecated = field.ShortDeprecated // 设置字段的简写弃用信息
+		setCompletion(item, field.Enum, field.Complete)
 
 		if fv := reflect.Indirect(field.Value.Ref); fv.Kind() == reflect.Bool {
 			item.NoOptDefVal = "true"
@@ -78,11 +95,72 @@ func FieldsWalk(structPtr any, prefix *Prefix, walFn func(field *FlagField, max
 // 返回值 :
 //
 //   - args 是包含结构体字段及其值的字符串切片，格式为 "--字段名 值"。
+//
+// 敏感字段不会出现在 args 中，需要传递给子进程时使用 FieldsToCmd。
 func FieldsToArgs(structPtr any) (args []string, err error) {
 	err = FieldsWalk(structPtr, nil, func(field *FlagField, _ int) {
+		if field.Secret {
+			return
+		}
 		for _, s := range rGet(field.Value.Ref) {
 			args = append(args, "--"+field.Name, s)
 		}
 	})
 	return
 }
+
+// FieldsToCmd 将结构体字段作为命令行参数追加到 cmd.Args，敏感字段不出现在命令行中，而是通过其第一个环境变量传递。
+//
+//	useFd 为 false 时以 ENV=值 追加到 cmd.Env；为 true 时将值写入管道，读取端追加到 cmd.ExtraFiles，
+//	子进程通过 <ENV>_FILE=/dev/fd/N 读取（仅支持类 Unix 系统），调用方应在 cmd.Start 后关闭 cmd.ExtraFiles。
+//	cmd.Env 为 nil 时以 os.Environ() 为基础；没有环境变量的敏感字段返回错误。
+func FieldsToCmd(cmd *exec.Cmd, structPtr any, useFd bool) error {
+	var errs []error
+	err := FieldsWalk(structPtr, nil, func(field *FlagField, _ int) {
+		values := rGet(field.Value.Ref)
+		if !field.Secret {
+			for _, s := range values {
+				cmd.Args = append(cmd.Args, "--"+field.Name, s)
+			}
+			return
+		}
+
+		if len(values) == 0 {
+			return
+		}
+
+		if len(field.Env) == 0 {
+			errs = append(errs, fmt.Errorf("secret --%s has no env to pass it", field.Name))
+			return
+		}
+
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+
+		key, value := strings.TrimSpace(field.Env[0]), values[0]
+		if !useFd {
+			cmd.Env = append(cmd.Env, key+"="+value)
+			return
+		}
+
+		r, w, e := os.Pipe()
+		if e != nil {
+			errs = append(errs, fmt.Errorf("secret --%s: %w", field.Name, e))
+			return
+		}
+
+		_, e = io.WriteString(w, value)
+		if e = errors.Join(e, w.Close()); e != nil {
+			r.Close()
+			errs = append(errs, fmt.Errorf("secret --%s: %w", field.Name, e))
+			return
+		}
+
+		// 环境变量优先于 <ENV>_FILE，去掉继承的同名环境变量
+		cmd.Env = slices.DeleteFunc(cmd.Env, func(s string) bool { return strings.HasPrefix(s, key+"=") })
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s%s=/dev/fd/%d", key, envFileSuffix, 3+len(cmd.ExtraFiles)))
+		cmd.ExtraFiles = append(cmd.ExtraFiles, r)
+	})
+	return errors.Join(append(errs, err)...)
+}
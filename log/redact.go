@@ -0,0 +1,92 @@
+package log
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	tagSecret  = "secret"
+	secretMask = "******"
+)
+
+// redact 返回 v 的副本，其中带有 secret 标签（如 `secret:"true"`）的非空字符串字段被替换为 ******，
+// 不包含敏感字段时原样返回 v
+func redact(v any) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !hasSecret(rv.Type(), map[reflect.Type]bool{}) {
+		return v
+	}
+
+	out := reflect.New(rv.Type()).Elem()
+	out.Set(rv)
+	redactValue(out, map[uintptr]reflect.Value{})
+	return out.Interface()
+}
+
+// redactValue 原地隐藏 v 中的敏感字段，指针指向的值被复制后再修改，
+// seen 记录已复制的指针，自引用的值只复制一次并保持引用关系
+func redactValue(v reflect.Value, seen map[uintptr]reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || !hasSecret(v.Type(), map[reflect.Type]bool{}) {
+			return
+		}
+		if cp, ok := seen[v.Pointer()]; ok {
+			v.Set(cp)
+			return
+		}
+
+		cp := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = cp
+		cp.Elem().Set(v.Elem())
+		redactValue(cp.Elem(), seen)
+		v.Set(cp)
+	case reflect.Struct:
+		for i, t := 0, v.Type(); i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			if isSecret(f) && fv.Kind() == reflect.String {
+				if fv.Len() > 0 {
+					fv.SetString(secretMask)
+				}
+				continue
+			}
+			redactValue(fv, seen)
+		}
+	}
+}
+
+func hasSecret(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return hasSecret(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				if isSecret(f) && f.Type.Kind() == reflect.String {
+					return true
+				}
+				if hasSecret(f.Type, seen) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isSecret 按与 config、flags 相同的规则（strconv.ParseBool）解析 secret 标签
+func isSecret(f reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(strings.TrimSpace(f.Tag.Get(tagSecret)))
+	return secret
+}
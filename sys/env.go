@@ -28,6 +28,14 @@ func (e *Env) Each(walkFn func(k, v string)) {
 	}
 }
 
+// Lookup 查找环境变量，签名与 os.LookupEnv 一致
+func (e *Env) Lookup(k string) (v string, ok bool) {
+	if e != nil {
+		v, ok = e.envMap[k]
+	}
+	return
+}
+
 func (e *Env) Set(k, v string) *Env {
 	if k = strings.TrimSpace(k); k != "" {
 		idx := slices.Index(e.keys, k)